@@ -76,7 +76,14 @@ func flashLED(args []string) func(logger *slog.Logger, ctrl *ws2811.Controller,
 			)
 		}
 
-		src := make(chan (map[int]ws2811.RGB))
+		labelLEDs(ctrl, func(i int, _ ws2811.RGB) string {
+			if i == index {
+				return "Identify"
+			}
+			return ""
+		})
+
+		src := make(chan ws2811.State)
 
 		g.Add(
 			func() error {
@@ -92,7 +99,7 @@ func flashLED(args []string) func(logger *slog.Logger, ctrl *ws2811.Controller,
 						vec[index] = ws2811.RGB{Red: 0, Green: 0, Blue: 0}
 					}
 					on = !on
-					src <- vec
+					src <- ws2811.StaticState(vec)
 				}
 
 				nxt()
@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -56,7 +57,18 @@ func randLEDs(logger *slog.Logger, ctrl *ws2811.Controller, cfg config.LED) erro
 		)
 	}
 
-	src := make(chan (map[int]ws2811.RGB))
+	var (
+		mu    sync.Mutex
+		shown ws2811.RGB
+	)
+
+	labelLEDs(ctrl, func(index int, _ ws2811.RGB) string {
+		mu.Lock()
+		defer mu.Unlock()
+		return shown.String()
+	})
+
+	src := make(chan ws2811.State)
 
 	g.Add(
 		func() error {
@@ -76,7 +88,10 @@ func randLEDs(logger *slog.Logger, ctrl *ws2811.Controller, cfg config.LED) erro
 					vec[i] = rgb
 				}
 				logger.Info("rendering", "color", rgb)
-				src <- vec
+				mu.Lock()
+				shown = rgb
+				mu.Unlock()
+				src <- ws2811.StaticState(vec)
 			}
 
 			nxt()
@@ -94,12 +94,27 @@ func Initialize() {
 }
 
 func execOp(op func(logger *slog.Logger, ctrl *ws2811.Controller, cfg config.LED) error) {
-	logger := config.NewLogger()
-
 	ledcfg := config.GetLED()
 
+	// The terminal output redraws the strip in place on stdout.
+	var out io.Writer = os.Stdout
+	if ledcfg.Output == config.OutputTerminal {
+		out = os.Stderr
+	}
+
+	logger := config.NewLogger(out)
+
+	drv, err := newDriver(ledcfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	ctrl := &ws2811.Controller{
-		Logger: logger,
+		Logger:     logger,
+		Driver:     drv,
+		FrameRate:  ledcfg.FrameRate,
+		Transition: ledcfg.Transition,
 		Options: []ws2811.Option{
 			func(opt *ws281x.ChannelOption) {
 				opt.Brightness = ledcfg.Brightness
@@ -117,3 +132,20 @@ func execOp(op func(logger *slog.Logger, ctrl *ws2811.Controller, cfg config.LED
 		os.Exit(1)
 	}
 }
+
+func newDriver(cfg config.LED) (ws2811.Driver, error) {
+	switch cfg.Output {
+	case config.OutputWS281x, "":
+		return ws2811.WS281x, nil
+	case config.OutputTerminal:
+		return &ws2811.Terminal{}, nil
+	}
+	return nil, fmt.Errorf("unknown output: %s", cfg.Output)
+}
+
+// labelLEDs sets the LED label function when rendering to a terminal.
+func labelLEDs(ctrl *ws2811.Controller, label func(index int, color ws2811.RGB) string) {
+	if term, ok := ctrl.Driver.(*ws2811.Terminal); ok {
+		term.Label = label
+	}
+}
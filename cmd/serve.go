@@ -41,7 +41,19 @@ func serve(logger *slog.Logger, ctrl *ws2811.Controller, ledcfg config.LED) erro
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	mcfg := config.GetMETAR()
+	mcfg, err := config.GetMETAR()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	var runways metar.Runways
+	if cfg.RunwaysPath != "" {
+		runways, err = metar.LoadRunways(cfg.RunwaysPath)
+		if err != nil {
+			return err
+		}
+		logger.Info("loaded runways", "airports", len(runways))
+	}
 
 	var g group.Group
 	{
@@ -64,17 +76,75 @@ func serve(logger *slog.Logger, ctrl *ws2811.Controller, ledcfg config.LED) erro
 		)
 	}
 
+	client := metar.Client{
+		BaseURL: mcfg.BaseURL,
+		Retry:   mcfg.Retry,
+		Breaker: &metar.CircuitBreaker{
+			Threshold: mcfg.BreakerThreshold,
+			Cooldown:  mcfg.BreakerCooldown,
+		},
+		BatchSize:   mcfg.BatchSize,
+		Concurrency: mcfg.Concurrency,
+		Format:      mcfg.Format,
+	}
+
+	provider, err := newProvider(logger, mcfg, client)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	srv := &metar.ColorServer{
 		Logger:              logger,
 		AirportIDs:          cfg.AirportIDs,
 		LEDIndexByAirportID: cfg.LEDIndexes,
 		Timeout:             mcfg.Timeout,
-		Client: metar.Client{
-			BaseURL: mcfg.BaseURL,
-		},
+		Client:              client,
+		Provider:            provider,
+		Mode:                cfg.Mode,
+		Gradient:            cfg.Gradient,
+		Runways:             runways,
+		Forecast:            cfg.Forecast,
+		Fog:                 cfg.Fog,
+		StaleAfter:          cfg.StaleAfter,
+		StaleColor:          cfg.StaleColor,
+		StaleDim:            cfg.StaleDim,
+		MissingColor:        cfg.MissingColor,
+		Rules:               cfg.Rules,
+		Minimums:            cfg.Minimums,
+		MinimumsColor:       cfg.MinimumsColor,
+		Lightning:           cfg.Lightning,
+		Wind:                cfg.Wind,
+		Attention:           cfg.Attention,
+		DensityAltitude:     cfg.DensityAltitude,
 	}
 
-	leds := make(chan (map[int]ws2811.RGB))
+	if mcfg.CachePath != "" {
+		srv.Cache = &metar.Cache{
+			Path:   mcfg.CachePath,
+			MaxAge: mcfg.CacheMaxAge,
+		}
+	}
+
+	airportIDs := make(map[int]string, len(cfg.LEDIndexes))
+	for id, idx := range cfg.LEDIndexes {
+		airportIDs[idx] = id
+	}
+
+	labelLEDs(ctrl, func(index int, _ ws2811.RGB) string {
+		id, ok := airportIDs[index]
+		if !ok {
+			return ""
+		}
+		if cfg.Mode.IsGradient() {
+			return id
+		}
+		if st, ok := srv.LEDStatus(index); ok {
+			return id + " " + st.String()
+		}
+		return id
+	})
+
+	leds := make(chan ws2811.State)
 
 	g.Add(
 		func() error {
@@ -103,3 +173,51 @@ func serve(logger *slog.Logger, ctrl *ws2811.Controller, ledcfg config.LED) erro
 
 	return g.Run()
 }
+
+func newProvider(logger *slog.Logger, cfg config.METAR, client metar.Client) (metar.Provider, error) {
+	// Each provider gets its own circuit breaker.
+	withBreaker := func(c metar.Client) metar.Client {
+		c.Breaker = &metar.CircuitBreaker{
+			Threshold: cfg.BreakerThreshold,
+			Cooldown:  cfg.BreakerCooldown,
+		}
+		return c
+	}
+
+	chain := &metar.Chain{
+		Logger:      logger,
+		MinCoverage: cfg.MinCoverage,
+		Demote:      cfg.Demote,
+	}
+
+	for _, name := range cfg.Providers {
+		var p metar.Provider
+		switch name {
+		case config.ProviderAWC:
+			p = client
+		case config.ProviderTGFTP:
+			p = metar.TGFTPProvider{
+				BaseURL: cfg.TGFTPURL,
+				Client:  withBreaker(client),
+			}
+		case config.ProviderBulkCSV:
+			p = metar.BulkCSVProvider{
+				URL:    cfg.BulkCSVURL,
+				Client: withBreaker(client),
+			}
+		case config.ProviderDirectory:
+			p = metar.DirectoryProvider{
+				Dir:    cfg.Directory,
+				Logger: logger,
+			}
+		default:
+			return nil, fmt.Errorf("invalid provider: %s", name)
+		}
+		chain.Providers = append(chain.Providers, metar.ChainLink{Name: name, Provider: p})
+	}
+
+	if len(chain.Providers) == 1 {
+		return chain.Providers[0].Provider, nil
+	}
+	return chain, nil
+}
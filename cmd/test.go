@@ -3,8 +3,10 @@ package cmd
 import (
 	"context"
 	"log/slog"
+	"maps"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -57,7 +59,22 @@ func testLEDs(logger *slog.Logger, ctrl *ws2811.Controller, cfg config.LED) erro
 		)
 	}
 
-	src := make(chan (map[int]ws2811.RGB))
+	// shown are the flight categories last sent to the strip, for labels.
+	var (
+		mu    sync.Mutex
+		shown map[int]metar.FlightCategory
+	)
+
+	labelLEDs(ctrl, func(index int, _ ws2811.RGB) string {
+		mu.Lock()
+		defer mu.Unlock()
+		if fc, ok := shown[index]; ok {
+			return fc.Name()
+		}
+		return ""
+	})
+
+	src := make(chan ws2811.State)
 
 	g.Add(
 		func() error {
@@ -78,7 +95,10 @@ func testLEDs(logger *slog.Logger, ctrl *ws2811.Controller, cfg config.LED) erro
 				select {
 				case <-tick.C:
 					logger.Info("rendering next", "vec", vec)
-					src <- metar.FlightCategoryToRGB(nil, vec)
+					mu.Lock()
+					shown = maps.Clone(vec)
+					mu.Unlock()
+					src <- ws2811.StaticState(metar.FlightCategoryToRGB(nil, vec))
 					nxt = next(nxt)
 				case <-ctx.Done():
 					tick.Stop()
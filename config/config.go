@@ -3,7 +3,6 @@ package config
 import (
 	"io"
 	"log/slog"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -44,7 +43,7 @@ func AddLogFlags(cmd *cobra.Command) {
 	viper.BindPFlag(cfgKeyLogAddSource, cmd.PersistentFlags().Lookup(flag))
 }
 
-func NewLogger() *slog.Logger {
+func NewLogger(out io.Writer) *slog.Logger {
 
 	cfg := GetLog()
 
@@ -64,7 +63,6 @@ func NewLogger() *slog.Logger {
 		opts.Level = slog.LevelDebug
 	}
 
-	var out io.Writer = os.Stdout
 	var h slog.Handler
 
 	switch cfg.Format {
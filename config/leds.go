@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/andrewmostello/metar-ws2811/ws2811"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -10,12 +12,23 @@ const (
 	cfgKeyLEDCount      = "led.count"
 	cfgKeyLEDBrightness = "led.brightness"
 	cfgKeyLEDGPIOPin    = "led.gpio_pin"
+	cfgKeyLEDOutput     = "led.output"
+	cfgKeyLEDFrameRate  = "led.frame_rate"
+	cfgKeyLEDTransition = "led.transition_ms"
+)
+
+const (
+	OutputWS281x   = "ws281x"
+	OutputTerminal = "terminal"
 )
 
 type LED struct {
 	Count      int
 	Brightness int
 	GPIOPin    int
+	Output     string
+	FrameRate  int
+	Transition time.Duration
 }
 
 func GetLED() LED {
@@ -23,6 +36,9 @@ func GetLED() LED {
 		Count:      viper.GetInt(cfgKeyLEDCount),
 		Brightness: viper.GetInt(cfgKeyLEDBrightness),
 		GPIOPin:    viper.GetInt(cfgKeyLEDGPIOPin),
+		Output:     viper.GetString(cfgKeyLEDOutput),
+		FrameRate:  viper.GetInt(cfgKeyLEDFrameRate),
+		Transition: time.Duration(viper.GetInt64(cfgKeyLEDTransition)) * time.Millisecond,
 	}
 }
 
@@ -38,4 +54,16 @@ func AddLEDFlags(cmd *cobra.Command) {
 	flag = "led-gpio-pin"
 	cmd.PersistentFlags().Int(flag, ws2811.DefaultGPIOPin, "GPIO pin of the data input to the LEDs.")
 	viper.BindPFlag(cfgKeyLEDGPIOPin, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "output"
+	cmd.PersistentFlags().String(flag, OutputWS281x, "Where to render LED colors. Options are ws281x and terminal. The terminal output draws on stdout and sends logs to stderr.")
+	viper.BindPFlag(cfgKeyLEDOutput, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "led-frame-rate"
+	cmd.PersistentFlags().Int(flag, ws2811.DefaultFrameRate, "Frames per second rendered for animations.")
+	viper.BindPFlag(cfgKeyLEDFrameRate, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "led-transition-ms"
+	cmd.PersistentFlags().Int64(flag, 0, "Milliseconds to crossfade between colors when they change, e.g. 2000. Zero changes colors immediately.")
+	viper.BindPFlag(cfgKeyLEDTransition, cmd.PersistentFlags().Lookup(flag))
 }
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/andrewmostello/metar-ws2811/metar"
+	"github.com/andrewmostello/metar-ws2811/ws2811"
 	"github.com/robfig/cron/v3"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -15,8 +17,52 @@ const (
 	cfgKeyServeRefreshCron = "serve.refresh_cron"
 	cfgKeyServeAirportIDs  = "serve.airport_ids"
 	cfgKeyServeLEDIndexes  = "serve.led_indexes"
+	cfgKeyServeMode        = "serve.mode"
+	cfgKeyServeGradient    = "serve.gradient"
+	cfgKeyServeRunways     = "serve.runways_path"
+	cfgKeyForecastHours    = "serve.forecast.hours"
+	cfgKeyForecastStep     = "serve.forecast.step_seconds"
+	cfgKeyForecastCurrent  = "serve.forecast.current_seconds"
+	cfgKeyFogHours         = "serve.fog.hours"
+	cfgKeyFogLookahead     = "serve.fog.lookahead_minutes"
+	cfgKeyServeStaleAfter  = "serve.stale_after_minutes"
+	cfgKeyServeStaleColor  = "serve.stale_color"
+	cfgKeyServeStaleDim    = "serve.stale_dim_percent"
+	cfgKeyServeMissing     = "serve.missing_color"
+	cfgKeyServeThresholds  = "serve.flight_category_thresholds"
+	cfgKeyMinimumsCeiling  = "serve.minimums.ceiling_ft"
+	cfgKeyMinimumsVis      = "serve.minimums.visibility_sm"
+	cfgKeyMinimumsXwind    = "serve.minimums.crosswind_kt"
+	cfgKeyMinimumsColor    = "serve.minimums.color"
+	cfgKeyLightning        = "serve.lightning.enabled"
+	cfgKeyLightningColor   = "serve.lightning.color"
+	cfgKeyWindThreshold    = "serve.wind.threshold_kt"
+	cfgKeyWindEffect       = "serve.wind.effect"
+	cfgKeyWindPeriod       = "serve.wind.period_ms"
+	cfgKeyWindDim          = "serve.wind.dim_percent"
+	cfgKeyAttention        = "serve.attention.enabled"
+	cfgKeyAttentionDur     = "serve.attention.duration_seconds"
+	cfgKeyDAThreshold      = "serve.density_altitude.threshold_ft"
+	cfgKeyDAMargin         = "serve.density_altitude.margin_ft"
+	cfgKeyDAColor          = "serve.density_altitude.color"
 	cfgKeyMETARBaseURL     = "metar.base_url"
 	cfgKeyMETARTimeout     = "metar.timeout_seconds"
+	cfgKeyMETARCachePath   = "metar.cache_path"
+	cfgKeyMETARCacheMaxAge = "metar.cache_max_age_minutes"
+	cfgKeyRetryMaxAttempts = "metar.retry.max_attempts"
+	cfgKeyRetryInitial     = "metar.retry.initial_backoff_ms"
+	cfgKeyRetryMax         = "metar.retry.max_backoff_seconds"
+	cfgKeyBreakerThreshold = "metar.breaker.threshold"
+	cfgKeyBreakerCooldown  = "metar.breaker.cooldown_seconds"
+	cfgKeyMETARBatchSize   = "metar.batch_size"
+	cfgKeyMETARConcurrency = "metar.concurrency"
+	cfgKeyMETARProviders   = "metar.providers"
+	cfgKeyMETARFormat      = "metar.format"
+	cfgKeyMETARMinCoverage = "metar.min_coverage_percent"
+	cfgKeyMETARDemote      = "metar.demote_minutes"
+	cfgKeyMETARDirectory   = "metar.directory"
+	cfgKeyMETARTGFTPURL    = "metar.tgftp_url"
+	cfgKeyMETARBulkCSVURL  = "metar.bulk_csv_url"
 )
 
 func durationInSeconds(dur int64) time.Duration {
@@ -26,6 +72,17 @@ func durationInSeconds(dur int64) time.Duration {
 	return time.Duration(dur) * time.Second
 }
 
+func optionalRGB(s string) (*ws2811.RGB, error) {
+	if s == "" {
+		return nil, nil
+	}
+	rgb, err := ws2811.ParseRGB(s)
+	if err != nil {
+		return nil, err
+	}
+	return &rgb, nil
+}
+
 func expandCommaSeparatedList(s []string) []string {
 	expanded := make([]string, 0, len(s))
 	for _, v := range s {
@@ -35,9 +92,25 @@ func expandCommaSeparatedList(s []string) []string {
 }
 
 type Serve struct {
-	RefreshCron cron.Schedule
-	AirportIDs  []string
-	LEDIndexes  map[string]int
+	RefreshCron     cron.Schedule
+	AirportIDs      []string
+	LEDIndexes      map[string]int
+	Mode            metar.Mode
+	Gradient        metar.Gradient
+	RunwaysPath     string
+	Forecast        metar.ForecastOptions
+	Fog             metar.FogOptions
+	StaleAfter      time.Duration
+	StaleColor      *ws2811.RGB
+	StaleDim        float64
+	MissingColor    *ws2811.RGB
+	Rules           *metar.Rules
+	Minimums        *metar.Minimums
+	MinimumsColor   *ws2811.RGB
+	Lightning       *metar.LightningOptions
+	Wind            *metar.WindOptions
+	Attention       *metar.AttentionOptions
+	DensityAltitude *metar.DensityAltitudeOptions
 }
 
 func GetServe() (Serve, error) {
@@ -74,13 +147,152 @@ func GetServe() (Serve, error) {
 		ledIndexMap[id] = last
 	}
 
+	mode := metar.Mode(viper.GetString(cfgKeyServeMode))
+	switch mode {
+	case metar.ModeFlightCategory, metar.ModeForecast, metar.ModeTemperature, metar.ModeCeilingVisibility,
+		metar.ModeWind, metar.ModeCrosswind, metar.ModeDensityAltitude, metar.ModeFog:
+	default:
+		return Serve{}, fmt.Errorf("invalid mode: %s", mode)
+	}
+
+	runwaysPath := viper.GetString(cfgKeyServeRunways)
+	if mode == metar.ModeCrosswind && runwaysPath == "" {
+		return Serve{}, fmt.Errorf("mode %s requires a runways path", mode)
+	}
+
+	var gradient metar.Gradient
+	if stops := expandCommaSeparatedList(viper.GetStringSlice(cfgKeyServeGradient)); len(stops) > 0 {
+		gradient, err = metar.ParseGradient(stops)
+		if err != nil {
+			return Serve{}, fmt.Errorf("invalid gradient: %w", err)
+		}
+	}
+
+	staleColor, err := optionalRGB(viper.GetString(cfgKeyServeStaleColor))
+	if err != nil {
+		return Serve{}, fmt.Errorf("invalid stale color: %w", err)
+	}
+
+	missingColor, err := optionalRGB(viper.GetString(cfgKeyServeMissing))
+	if err != nil {
+		return Serve{}, fmt.Errorf("invalid missing color: %w", err)
+	}
+
+	rules, err := parseRules(expandCommaSeparatedList(viper.GetStringSlice(cfgKeyServeThresholds)))
+	if err != nil {
+		return Serve{}, fmt.Errorf("invalid flight category thresholds: %w", err)
+	}
+
+	var minimums *metar.Minimums
+	if mins := (metar.Minimums{
+		Ceiling:    viper.GetFloat64(cfgKeyMinimumsCeiling),
+		Visibility: viper.GetFloat64(cfgKeyMinimumsVis),
+		Crosswind:  viper.GetFloat64(cfgKeyMinimumsXwind),
+	}); !mins.IsZero() {
+		minimums = &mins
+	}
+
+	minimumsColor, err := optionalRGB(viper.GetString(cfgKeyMinimumsColor))
+	if err != nil {
+		return Serve{}, fmt.Errorf("invalid minimums color: %w", err)
+	}
+
+	var lightning *metar.LightningOptions
+	if viper.GetBool(cfgKeyLightning) {
+		c, err := ws2811.ParseRGB(viper.GetString(cfgKeyLightningColor))
+		if err != nil {
+			return Serve{}, fmt.Errorf("invalid lightning color: %w", err)
+		}
+		lightning = &metar.LightningOptions{Color: c}
+	}
+
+	var wind *metar.WindOptions
+	if threshold := viper.GetFloat64(cfgKeyWindThreshold); threshold > 0 {
+		effect := metar.WindEffect(viper.GetString(cfgKeyWindEffect))
+		switch effect {
+		case metar.WindEffectBlink, metar.WindEffectPulse:
+		default:
+			return Serve{}, fmt.Errorf("invalid wind effect: %s", effect)
+		}
+		wind = &metar.WindOptions{
+			Threshold: threshold,
+			Effect:    effect,
+			Period:    time.Duration(viper.GetInt64(cfgKeyWindPeriod)) * time.Millisecond,
+			Dim:       viper.GetFloat64(cfgKeyWindDim) / 100,
+		}
+	}
+
+	var densityAltitude *metar.DensityAltitudeOptions
+	if threshold, margin := viper.GetFloat64(cfgKeyDAThreshold), viper.GetFloat64(cfgKeyDAMargin); threshold > 0 || margin > 0 {
+		c, err := ws2811.ParseRGB(viper.GetString(cfgKeyDAColor))
+		if err != nil {
+			return Serve{}, fmt.Errorf("invalid density altitude color: %w", err)
+		}
+		densityAltitude = &metar.DensityAltitudeOptions{
+			Threshold: threshold,
+			Margin:    margin,
+			Color:     c,
+		}
+	}
+
+	var attention *metar.AttentionOptions
+	if viper.GetBool(cfgKeyAttention) {
+		attention = &metar.AttentionOptions{
+			Duration: durationInSeconds(viper.GetInt64(cfgKeyAttentionDur)),
+		}
+	}
+
 	return Serve{
 		RefreshCron: refreshSchedule,
 		AirportIDs:  ids,
 		LEDIndexes:  ledIndexMap,
+		Mode:        mode,
+		Gradient:    gradient,
+		RunwaysPath: runwaysPath,
+		Forecast: metar.ForecastOptions{
+			Hours:   viper.GetInt(cfgKeyForecastHours),
+			Step:    durationInSeconds(viper.GetInt64(cfgKeyForecastStep)),
+			Current: durationInSeconds(viper.GetInt64(cfgKeyForecastCurrent)),
+		},
+		Fog: metar.FogOptions{
+			Hours:     viper.GetInt(cfgKeyFogHours),
+			Lookahead: time.Duration(viper.GetInt64(cfgKeyFogLookahead)) * time.Minute,
+		},
+		StaleAfter:      time.Duration(viper.GetInt64(cfgKeyServeStaleAfter)) * time.Minute,
+		StaleColor:      staleColor,
+		StaleDim:        viper.GetFloat64(cfgKeyServeStaleDim) / 100,
+		MissingColor:    missingColor,
+		Rules:           rules,
+		Minimums:        minimums,
+		MinimumsColor:   minimumsColor,
+		Lightning:       lightning,
+		Wind:            wind,
+		Attention:       attention,
+		DensityAltitude: densityAltitude,
 	}, nil
 }
 
+func parseRules(thresholds []string) (*metar.Rules, error) {
+	var out metar.Rules
+	for _, s := range thresholds {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		t, err := metar.ParseThreshold(s)
+		if err != nil {
+			return nil, err
+		}
+		out.Thresholds = append(out.Thresholds, t)
+	}
+	if len(out.Thresholds) == 0 {
+		return &metar.FAARules, nil
+	}
+	if err := out.Validate(); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 func AddServeFlags(cmd *cobra.Command) {
 	flag := "serve-refresh-cron"
 	cmd.PersistentFlags().String(flag, "*/15 * * * *", "Cron format schedule on which to refresh METAR data.")
@@ -93,18 +305,198 @@ func AddServeFlags(cmd *cobra.Command) {
 	flag = "serve-led-indexes"
 	cmd.PersistentFlags().StringSlice(flag, []string{}, "Index of LED for a specified airport ID. Arguments should be in the format of 'airport_id=led_index', e.g. \"KBOS=15\". Accepts multiple arguments and will explode any comma separated lists.")
 	viper.BindPFlag(cfgKeyServeLEDIndexes, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-mode"
+	cmd.PersistentFlags().String(flag, string(metar.ModeFlightCategory), "What to display. Options are flight_category, forecast, temperature, ceiling_visibility, wind, crosswind, density_altitude and fog.")
+	viper.BindPFlag(cfgKeyServeMode, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-gradient"
+	cmd.PersistentFlags().StringSlice(flag, nil, "Gradient of value:color stops with hex colors used by the gradient modes, e.g. -20:#0000ff,0:#ffffff,35:#ff0000. Ceiling and visibility are normalized from 0 to 4, where 1, 2 and 3 are the LIFR, IFR and MVFR limits. Fog mode uses the projected temperature/dewpoint spread in degrees Celsius. Repeating a value makes a step. Defaults to the gradient of the mode.")
+	viper.BindPFlag(cfgKeyServeGradient, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-runways-path"
+	cmd.PersistentFlags().String(flag, "", "Runways file in the OurAirports runways.csv format, or a .json array with the same fields. Used for crosswinds by the crosswind mode and personal minimums.")
+	viper.BindPFlag(cfgKeyServeRunways, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-forecast-hours"
+	cmd.PersistentFlags().Int(flag, metar.DefaultForecastHours, "Hours ahead to loop through in forecast mode.")
+	viper.BindPFlag(cfgKeyForecastHours, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-forecast-step-seconds"
+	cmd.PersistentFlags().Int(flag, int(metar.DefaultForecastStep/time.Second), "Seconds to display each forecast hour in forecast mode.")
+	viper.BindPFlag(cfgKeyForecastStep, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-forecast-current-seconds"
+	cmd.PersistentFlags().Int(flag, int(metar.DefaultForecastCurrent/time.Second), "Seconds to display the current conditions at the start of each forecast loop.")
+	viper.BindPFlag(cfgKeyForecastCurrent, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-fog-hours"
+	cmd.PersistentFlags().Int(flag, metar.DefaultFogHours, "Hours of observations to request for the previous observation of each airport in fog mode.")
+	viper.BindPFlag(cfgKeyFogHours, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-fog-lookahead-minutes"
+	cmd.PersistentFlags().Int(flag, int(metar.DefaultFogLookahead/time.Minute), "Minutes ahead to project a shrinking temperature/dewpoint spread in fog mode.")
+	viper.BindPFlag(cfgKeyFogLookahead, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-stale-after-minutes"
+	cmd.PersistentFlags().Int(flag, int(metar.DefaultStaleAfter/time.Minute), "Minutes after observation that a METAR is displayed as stale.")
+	viper.BindPFlag(cfgKeyServeStaleAfter, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-stale-color"
+	cmd.PersistentFlags().String(flag, "", "Color of airports with stale METARs, e.g. \"#ffff00\" or \"255,255,0\". Default is to dim the flight category color.")
+	viper.BindPFlag(cfgKeyServeStaleColor, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-stale-dim-percent"
+	cmd.PersistentFlags().Int(flag, int(metar.DefaultStaleDim*100), "Brightness of airports with stale METARs as a percent of their flight category color.")
+	viper.BindPFlag(cfgKeyServeStaleDim, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-missing-color"
+	cmd.PersistentFlags().String(flag, metar.DefaultMissingColor.String(), "Color of airports without a METAR.")
+	viper.BindPFlag(cfgKeyServeMissing, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-flight-category-thresholds"
+	cmd.PersistentFlags().StringSlice(flag, []string{}, "Flight category thresholds from worst to best, replacing the FAA definitions, e.g. \"LIFR:ceiling<500:visibility<1600m\". Limits are < or <= a ceiling in feet or a visibility in statute miles, meters (m) or kilometers (km). Conditions within no threshold are VFR.")
+	viper.BindPFlag(cfgKeyServeThresholds, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-minimums-ceiling-ft"
+	cmd.PersistentFlags().Int(flag, 0, "Personal minimum ceiling in feet. VFR airports below any personal minimum are displayed in the minimums color.")
+	viper.BindPFlag(cfgKeyMinimumsCeiling, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-minimums-visibility-sm"
+	cmd.PersistentFlags().Float64(flag, 0, "Personal minimum visibility in statute miles.")
+	viper.BindPFlag(cfgKeyMinimumsVis, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-minimums-crosswind-kt"
+	cmd.PersistentFlags().Int(flag, 0, "Personal maximum crosswind in knots, gusts included. Without --serve-runways-path, runway headings are unknown and the total wind is checked against it.")
+	viper.BindPFlag(cfgKeyMinimumsXwind, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-minimums-color"
+	cmd.PersistentFlags().String(flag, metar.DefaultMinimumsColor.String(), "Color of VFR airports below personal minimums.")
+	viper.BindPFlag(cfgKeyMinimumsColor, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-lightning"
+	cmd.PersistentFlags().Bool(flag, false, "Flash airports reporting thunderstorms or lightning.")
+	viper.BindPFlag(cfgKeyLightning, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-lightning-color"
+	cmd.PersistentFlags().String(flag, metar.DefaultLightningColor.String(), "Color of lightning flashes.")
+	viper.BindPFlag(cfgKeyLightningColor, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-wind-threshold-kt"
+	cmd.PersistentFlags().Int(flag, 0, "Animate airports with wind or gusts of at least this many knots. Zero disables the animation.")
+	viper.BindPFlag(cfgKeyWindThreshold, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-wind-effect"
+	cmd.PersistentFlags().String(flag, string(metar.WindEffectBlink), "How high wind is animated: blink or pulse.")
+	viper.BindPFlag(cfgKeyWindEffect, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-wind-period-ms"
+	cmd.PersistentFlags().Int64(flag, metar.DefaultWindPeriod.Milliseconds(), "Length of one blink or pulse in milliseconds without gusts. Every 10 knots of gust factor doubles the rate.")
+	viper.BindPFlag(cfgKeyWindPeriod, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-wind-dim-percent"
+	cmd.PersistentFlags().Float64(flag, metar.DefaultWindDim*100, "Brightness of the dimmed phase of a blink or pulse as a percentage.")
+	viper.BindPFlag(cfgKeyWindDim, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-density-altitude-threshold-ft"
+	cmd.PersistentFlags().Int(flag, 0, "Flag airports whose density altitude is at or above this many feet. Zero disables the threshold.")
+	viper.BindPFlag(cfgKeyDAThreshold, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-density-altitude-margin-ft"
+	cmd.PersistentFlags().Int(flag, 0, "Flag airports whose density altitude is more than this many feet above field elevation. Zero disables the margin.")
+	viper.BindPFlag(cfgKeyDAMargin, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-density-altitude-color"
+	cmd.PersistentFlags().String(flag, metar.DefaultDensityAltitudeColor.String(), "Warning color that airports with high density altitude swell toward.")
+	viper.BindPFlag(cfgKeyDAColor, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-attention"
+	cmd.PersistentFlags().Bool(flag, false, "Pulse airports whose flight category changed at the last refresh.")
+	viper.BindPFlag(cfgKeyAttention, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "serve-attention-duration-seconds"
+	cmd.PersistentFlags().Int64(flag, int64(metar.DefaultAttentionDuration/time.Second), "Seconds an airport pulses after its flight category changes.")
+	viper.BindPFlag(cfgKeyAttentionDur, cmd.PersistentFlags().Lookup(flag))
 }
 
+const (
+	ProviderAWC       = "awc"
+	ProviderTGFTP     = "tgftp"
+	ProviderBulkCSV   = "awc-bulk-csv"
+	ProviderDirectory = "directory"
+)
+
 type METAR struct {
-	BaseURL string
-	Timeout time.Duration
+	BaseURL          string
+	Timeout          time.Duration
+	CachePath        string
+	CacheMaxAge      time.Duration
+	Retry            metar.RetryPolicy
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	BatchSize        int
+	Concurrency      int
+	Providers        []string
+	MinCoverage      float64
+	Demote           time.Duration
+	Format           metar.Format
+	Directory        string
+	TGFTPURL         string
+	BulkCSVURL       string
 }
 
-func GetMETAR() METAR {
-	return METAR{
-		BaseURL: viper.GetString(cfgKeyMETARBaseURL),
-		Timeout: durationInSeconds(viper.GetInt64(cfgKeyMETARTimeout)),
+func GetMETAR() (METAR, error) {
+
+	providers := expandCommaSeparatedList(viper.GetStringSlice(cfgKeyMETARProviders))
+	if len(providers) == 0 {
+		return METAR{}, fmt.Errorf("no providers specified")
+	}
+	seen := make(map[string]bool, len(providers))
+	for _, provider := range providers {
+		switch provider {
+		case ProviderAWC, ProviderTGFTP, ProviderBulkCSV:
+		case ProviderDirectory:
+			if viper.GetString(cfgKeyMETARDirectory) == "" {
+				return METAR{}, fmt.Errorf("directory provider requires a directory")
+			}
+		default:
+			return METAR{}, fmt.Errorf("invalid provider: %s", provider)
+		}
+		if seen[provider] {
+			return METAR{}, fmt.Errorf("duplicate provider: %s", provider)
+		}
+		seen[provider] = true
+	}
+
+	format := metar.Format(viper.GetString(cfgKeyMETARFormat))
+	switch format {
+	case metar.FormatJSON, metar.FormatXML, metar.FormatCSV:
+	default:
+		return METAR{}, fmt.Errorf("invalid format: %s", format)
 	}
+
+	return METAR{
+		BaseURL:     viper.GetString(cfgKeyMETARBaseURL),
+		Timeout:     durationInSeconds(viper.GetInt64(cfgKeyMETARTimeout)),
+		CachePath:   viper.GetString(cfgKeyMETARCachePath),
+		CacheMaxAge: time.Duration(viper.GetInt64(cfgKeyMETARCacheMaxAge)) * time.Minute,
+		Retry: metar.RetryPolicy{
+			MaxAttempts:    viper.GetInt(cfgKeyRetryMaxAttempts),
+			InitialBackoff: time.Duration(viper.GetInt64(cfgKeyRetryInitial)) * time.Millisecond,
+			MaxBackoff:     durationInSeconds(viper.GetInt64(cfgKeyRetryMax)),
+		},
+		BreakerThreshold: viper.GetInt(cfgKeyBreakerThreshold),
+		BreakerCooldown:  durationInSeconds(viper.GetInt64(cfgKeyBreakerCooldown)),
+		BatchSize:        viper.GetInt(cfgKeyMETARBatchSize),
+		Concurrency:      viper.GetInt(cfgKeyMETARConcurrency),
+		Providers:        providers,
+		MinCoverage:      float64(viper.GetInt(cfgKeyMETARMinCoverage)) / 100,
+		Demote:           time.Duration(viper.GetInt64(cfgKeyMETARDemote)) * time.Minute,
+		Format:           format,
+		Directory:        viper.GetString(cfgKeyMETARDirectory),
+		TGFTPURL:         viper.GetString(cfgKeyMETARTGFTPURL),
+		BulkCSVURL:       viper.GetString(cfgKeyMETARBulkCSVURL),
+	}, nil
 }
 
 func AddMetarFlags(cmd *cobra.Command) {
@@ -115,4 +507,68 @@ func AddMetarFlags(cmd *cobra.Command) {
 	flag = "metar-base-url"
 	cmd.PersistentFlags().String(flag, "https://aviationweather.gov/api/data", "Base URL for Aviation Weather Center data API for METAR requests.")
 	viper.BindPFlag(cfgKeyMETARBaseURL, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-cache-path"
+	cmd.PersistentFlags().String(flag, metar.DefaultCachePath, "File to keep the last good METARs in, to serve when a refresh fails. Empty disables the cache.")
+	viper.BindPFlag(cfgKeyMETARCachePath, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-cache-max-age-minutes"
+	cmd.PersistentFlags().Int(flag, int(metar.DefaultCacheMaxAge/time.Minute), "Minutes after observation that a cached METAR is served.")
+	viper.BindPFlag(cfgKeyMETARCacheMaxAge, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-retry-max-attempts"
+	cmd.PersistentFlags().Int(flag, 3, "Total attempts for a METAR request, including retries of failed requests.")
+	viper.BindPFlag(cfgKeyRetryMaxAttempts, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-retry-initial-backoff-ms"
+	cmd.PersistentFlags().Int(flag, int(metar.DefaultRetryInitialBackoff/time.Millisecond), "Milliseconds of maximum backoff before the first retry. Doubles for each retry after.")
+	viper.BindPFlag(cfgKeyRetryInitial, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-retry-max-backoff-seconds"
+	cmd.PersistentFlags().Int(flag, int(metar.DefaultRetryMaxBackoff/time.Second), "Seconds of maximum backoff between retries.")
+	viper.BindPFlag(cfgKeyRetryMax, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-breaker-threshold"
+	cmd.PersistentFlags().Int(flag, 5, "Consecutive failed METAR requests before requests stop for the cooldown. 0 disables the circuit breaker.")
+	viper.BindPFlag(cfgKeyBreakerThreshold, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-breaker-cooldown-seconds"
+	cmd.PersistentFlags().Int(flag, int(metar.DefaultBreakerCooldown/time.Second), "Seconds to stop METAR requests once the circuit breaker opens.")
+	viper.BindPFlag(cfgKeyBreakerCooldown, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-batch-size"
+	cmd.PersistentFlags().Int(flag, 100, "Most airport IDs to put in a single METAR request. 0 puts every airport in one request.")
+	viper.BindPFlag(cfgKeyMETARBatchSize, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-concurrency"
+	cmd.PersistentFlags().Int(flag, metar.DefaultConcurrency, "Most METAR requests to make at once.")
+	viper.BindPFlag(cfgKeyMETARConcurrency, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-providers"
+	cmd.PersistentFlags().StringSlice(flag, []string{ProviderAWC}, "Ordered sources of METAR data. Later sources are used when earlier ones fail or are missing airports. Options are awc, tgftp, awc-bulk-csv, and directory.")
+	viper.BindPFlag(cfgKeyMETARProviders, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-min-coverage-percent"
+	cmd.PersistentFlags().Int(flag, int(metar.DefaultMinCoverage*100), "Percent of requested airports a METAR source must return to be considered healthy.")
+	viper.BindPFlag(cfgKeyMETARMinCoverage, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-demote-minutes"
+	cmd.PersistentFlags().Int(flag, int(metar.DefaultDemote/time.Minute), "Minutes a METAR source is tried after the others once it fails.")
+	viper.BindPFlag(cfgKeyMETARDemote, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-format"
+	cmd.PersistentFlags().String(flag, string(metar.FormatJSON), "Response format requested from the awc provider. Options are json, xml, and csv.")
+	viper.BindPFlag(cfgKeyMETARFormat, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-directory"
+	cmd.PersistentFlags().String(flag, "", "Directory of raw METAR text files for the directory provider.")
+	viper.BindPFlag(cfgKeyMETARDirectory, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-tgftp-url"
+	cmd.PersistentFlags().String(flag, metar.DefaultTGFTPURL, "URL of the NOAA station files for the tgftp provider.")
+	viper.BindPFlag(cfgKeyMETARTGFTPURL, cmd.PersistentFlags().Lookup(flag))
+
+	flag = "metar-bulk-csv-url"
+	cmd.PersistentFlags().String(flag, metar.DefaultBulkCSVURL, "URL of the METAR cache file for the awc-bulk-csv provider.")
+	viper.BindPFlag(cfgKeyMETARBulkCSVURL, cmd.PersistentFlags().Lookup(flag))
 }
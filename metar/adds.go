@@ -0,0 +1,145 @@
+package metar
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// addsFields decode the fields of the ADDS METAR schema, except the
+// repeated sky conditions.
+var addsFields = map[string]func(m *METAR, v string) error{
+	"raw_text": func(m *METAR, v string) error {
+		m.RawObservation = v
+		return nil
+	},
+	"station_id": func(m *METAR, v string) error {
+		m.ICAOID = v
+		return nil
+	},
+	"observation_time": func(m *METAR, v string) error {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return err
+		}
+		m.ObservationTime = Time(t.UTC())
+		return nil
+	},
+	"latitude":                      addsFloat(func(m *METAR, f float64) { m.Latitude = f }),
+	"longitude":                     addsFloat(func(m *METAR, f float64) { m.Longitude = f }),
+	"temp_c":                        addsFloat(func(m *METAR, f float64) { m.Temperature, m.HasTemperature = f, true }),
+	"dewpoint_c":                    addsFloat(func(m *METAR, f float64) { m.Dewpoint, m.HasDewpoint = f, true }),
+	"wind_speed_kt":                 addsFloat(func(m *METAR, f float64) { m.WindSpeed = f }),
+	"wind_gust_kt":                  addsFloat(func(m *METAR, f float64) { m.WindGust = f }),
+	"sea_level_pressure_mb":         addsFloat(func(m *METAR, f float64) { m.SeaLevelPressure = f }),
+	"elevation_m":                   addsFloat(func(m *METAR, f float64) { m.Elevation, m.HasElevation = f, true }),
+	"three_hr_pressure_tendency_mb": addsFloatPtr(func(m *METAR) **float64 { return &m.PressureTendency }),
+	"maxT_c":                        addsFloatPtr(func(m *METAR) **float64 { return &m.MaxTemperature }),
+	"minT_c":                        addsFloatPtr(func(m *METAR) **float64 { return &m.MinTemperature }),
+	"maxT24hr_c":                    addsFloatPtr(func(m *METAR) **float64 { return &m.MaxTemperature24Hours }),
+	"minT24hr_c":                    addsFloatPtr(func(m *METAR) **float64 { return &m.MinTemperature24Hours }),
+	"precip_in":                     addsFloatPtr(func(m *METAR) **float64 { return &m.Precipitation }),
+	"pcp3hr_in":                     addsFloatPtr(func(m *METAR) **float64 { return &m.Precipitation3Hour }),
+	"pcp6hr_in":                     addsFloatPtr(func(m *METAR) **float64 { return &m.Precipitation6Hour }),
+	"pcp24hr_in":                    addsFloatPtr(func(m *METAR) **float64 { return &m.Precipitation24Hour }),
+	"snow_in":                       addsFloatPtr(func(m *METAR) **float64 { return &m.Snow }),
+	"vert_vis_ft":                   addsFloatPtr(func(m *METAR) **float64 { return &m.VerticalVisibility }),
+	"altim_in_hg": addsFloat(func(m *METAR, f float64) {
+		m.Altimeter = math.Round(f*hectopascalsPerInHg*10) / 10
+	}),
+	"wind_dir_degrees": func(m *METAR, v string) error {
+		return m.WindDirection.UnmarshalJSON(addsQuote(v))
+	},
+	"visibility_statute_mi": func(m *METAR, v string) error {
+		var vis Visibility
+		if err := vis.UnmarshalJSON(addsQuote(v)); err != nil {
+			return err
+		}
+		m.Visibility = &vis
+		return nil
+	},
+	"wx_string": func(m *METAR, v string) error {
+		m.WxString = v
+		return nil
+	},
+	"metar_type": func(m *METAR, v string) error {
+		m.MetarType = METARType(v)
+		return nil
+	},
+	"auto":      addsBool(func(m *METAR, b bool) { m.Auto = b }),
+	"corrected": addsBool(func(m *METAR, b bool) { m.Corrected = b }),
+}
+
+// setADDSField decodes an ADDS field into m, ignoring unknown fields.
+func setADDSField(m *METAR, name string, v string) error {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil
+	}
+	f, ok := addsFields[name]
+	if !ok {
+		return nil
+	}
+	if err := f(m, v); err != nil {
+		return fmt.Errorf("invalid %s %q: %w", name, v, err)
+	}
+	return nil
+}
+
+func addADDSSkyCondition(m *METAR, cover string, base string) error {
+	cover = strings.TrimSpace(cover)
+	if cover == "" {
+		return nil
+	}
+	lyr := CloudLayer{Cover: CloudCover(cover)}
+	if base = strings.TrimSpace(base); base != "" {
+		f, err := strconv.ParseFloat(base, 64)
+		if err != nil {
+			return fmt.Errorf("invalid cloud_base_ft_agl %q: %w", base, err)
+		}
+		lyr.Base = &f
+	}
+	m.Clouds = append(m.Clouds, lyr)
+	return nil
+}
+
+func addsQuote(v string) []byte {
+	b, _ := json.Marshal(v)
+	return b
+}
+
+func addsFloat(set func(m *METAR, f float64)) func(m *METAR, v string) error {
+	return func(m *METAR, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		set(m, f)
+		return nil
+	}
+}
+
+func addsFloatPtr(field func(m *METAR) **float64) func(m *METAR, v string) error {
+	return func(m *METAR, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		*field(m) = &f
+		return nil
+	}
+}
+
+func addsBool(set func(m *METAR, b bool)) func(m *METAR, v string) error {
+	return func(m *METAR, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		set(m, b)
+		return nil
+	}
+}
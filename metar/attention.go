@@ -0,0 +1,71 @@
+package metar
+
+import (
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+const (
+	DefaultAttentionDuration = 10 * time.Second
+	DefaultAttentionPeriod   = time.Second
+	DefaultAttentionDim      = 0.2
+)
+
+// AttentionOptions configure the pulse on airports whose flight category
+// changed at a refresh.
+type AttentionOptions struct {
+	// Duration is how long an airport pulses after its category changes.
+	// Zero uses DefaultAttentionDuration.
+	Duration time.Duration
+	// Period is the length of one pulse. Zero uses DefaultAttentionPeriod.
+	Period time.Duration
+	// Dim is the brightness at the bottom of a pulse relative to the full
+	// color. Zero uses DefaultAttentionDim.
+	Dim float64
+}
+
+func (o AttentionOptions) effect(base ws2811.Effect, start time.Time) ws2811.Effect {
+	out := ws2811.Attention{
+		Base:     base,
+		Start:    start,
+		Duration: o.Duration,
+		Dim:      o.Dim,
+		Period:   o.Period,
+	}
+	if out.Duration <= 0 {
+		out.Duration = DefaultAttentionDuration
+	}
+	if out.Dim <= 0 {
+		out.Dim = DefaultAttentionDim
+	}
+	if out.Period <= 0 {
+		out.Period = DefaultAttentionPeriod
+	}
+	return out
+}
+
+// attention pulses the LEDs in state whose flight category changed since the
+// previous refresh.
+func (srv *ColorServer) attention(now time.Time, statuses map[int]Status, state ws2811.State) {
+
+	prev := srv.categories
+
+	srv.categories = make(map[int]FlightCategory, len(statuses))
+	for idx, st := range statuses {
+		if !st.Missing {
+			srv.categories[idx] = st.FlightCategory
+		}
+	}
+
+	if srv.Attention == nil || prev == nil {
+		return
+	}
+
+	for idx, fc := range srv.categories {
+		if pfc, ok := prev[idx]; !ok || pfc == fc {
+			continue
+		}
+		state[idx] = srv.Attention.effect(state[idx], now)
+	}
+}
@@ -0,0 +1,97 @@
+package metar_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+// everySchedule is a cron.Schedule with a fixed delay.
+type everySchedule time.Duration
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+func TestColorServerAttention(t *testing.T) {
+
+	raws := []map[string]string{
+		{
+			"KFIT": "KFIT 141352Z 26010KT 10SM CLR 12/M01 A2985",
+			"KORH": "KORH 141354Z 26010KT 10SM CLR 12/M01 A2985",
+		},
+		{
+			"KFIT": "KFIT 141452Z 26010KT 2SM BR OVC008 12/11 A2985",
+			"KORH": "KORH 141454Z 26010KT 10SM CLR 12/M01 A2985",
+		},
+	}
+
+	var (
+		mu      sync.Mutex
+		refresh int
+	)
+
+	provider := metar.ProviderFunc(func(ctx context.Context, airportIDs ...string) (map[string]metar.METAR, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		out := make(map[string]metar.METAR)
+		for id, raw := range raws[min(refresh, len(raws)-1)] {
+			m, err := metar.ParseRawAt(raw, time.Date(2024, 4, 14, 18, 0, 0, 0, time.UTC))
+			if err != nil {
+				return nil, err
+			}
+			out[id] = m
+		}
+		refresh++
+		return out, nil
+	})
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"KFIT", "KORH"},
+		LEDIndexByAirportID: map[string]int{
+			"KFIT": 0,
+			"KORH": 1,
+		},
+		Provider:   provider,
+		StaleAfter: 100000 * time.Hour,
+		Attention: &metar.AttentionOptions{
+			Duration: time.Hour,
+			Period:   time.Second,
+			Dim:      0.5,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leds := make(chan ws2811.State)
+
+	go srv.Serve(ctx, everySchedule(10*time.Millisecond), leds)
+
+	first := <-leds
+	second := <-leds
+
+	vfr := metar.DefaultColors[metar.FlightCategoryVFR]
+	ifr := metar.DefaultColors[metar.FlightCategoryIFR]
+
+	// The first refresh has nothing to compare with, so nothing pulses.
+	for _, at := range []time.Time{time.Now(), time.Now().Add(time.Minute)} {
+		if got := first.Colors(at)[0]; got != vfr {
+			t.Fatalf("expected %v at 0 on the first refresh, got %v", vfr, got)
+		}
+	}
+
+	// Pulses start dimmed.
+	colors := second.Colors(time.Now())
+	if colors[0] == ifr {
+		t.Fatalf("expected KFIT to pulse after changing to IFR, got %v", colors[0])
+	}
+	if colors[1] != vfr {
+		t.Fatalf("expected %v at 1, got %v", vfr, colors[1])
+	}
+}
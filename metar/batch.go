@@ -0,0 +1,119 @@
+package metar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultConcurrency = 4
+)
+
+// BatchFailure is a batch of airports whose request failed.
+type BatchFailure struct {
+	AirportIDs []string
+	Err        error
+}
+
+// BatchError is returned when some batches of a request fail. Results from
+// the batches that succeeded are returned along with it.
+type BatchError struct {
+	Batches  int
+	Failures []BatchFailure
+}
+
+func (e *BatchError) Error() string {
+	msgs := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", strings.Join(f.AirportIDs, ","), f.Err))
+	}
+	return fmt.Sprintf("%d of %d batches failed: %s", len(e.Failures), e.Batches, strings.Join(msgs, "; "))
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		errs = append(errs, f.Err)
+	}
+	return errs
+}
+
+// FailedAirportIDs returns the airports in every failed batch.
+func (e *BatchError) FailedAirportIDs() []string {
+	var out []string
+	for _, f := range e.Failures {
+		out = append(out, f.AirportIDs...)
+	}
+	return out
+}
+
+// batches splits airportIDs, without duplicates, into batches of at most size.
+func batches(airportIDs []string, size int) [][]string {
+	seen := make(map[string]struct{}, len(airportIDs))
+	ids := make([]string, 0, len(airportIDs))
+	for _, id := range airportIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	if size <= 0 || size >= len(ids) {
+		return [][]string{ids}
+	}
+
+	out := make([][]string, 0, (len(ids)+size-1)/size)
+	for len(ids) > 0 {
+		n := min(size, len(ids))
+		out = append(out, ids[:n:n])
+		ids = ids[n:]
+	}
+	return out
+}
+
+func (c Client) concurrency() int {
+	if c.Concurrency > 0 {
+		return c.Concurrency
+	}
+	return DefaultConcurrency
+}
+
+// forEachBatch calls fn for each batch of airportIDs concurrently.
+func (c Client) forEachBatch(ctx context.Context, airportIDs []string, fn func(ctx context.Context, batch []string) error) error {
+
+	bs := batches(airportIDs, c.BatchSize)
+
+	sem := make(chan struct{}, c.concurrency())
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures []BatchFailure
+	)
+
+	for _, batch := range bs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(batch []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(ctx, batch); err != nil {
+				mu.Lock()
+				failures = append(failures, BatchFailure{AirportIDs: batch, Err: err})
+				mu.Unlock()
+			}
+		}(batch)
+	}
+
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return &BatchError{Batches: len(bs), Failures: failures}
+	}
+
+	return nil
+}
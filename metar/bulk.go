@@ -0,0 +1,73 @@
+package metar
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+)
+
+const (
+	DefaultBulkCSVURL = "https://aviationweather.gov/data/cache/metars.cache.csv.gz"
+)
+
+// BulkCSVProvider gets METARs from the AWC bulk cache file, which has the
+// latest observation for every station in one gzipped CSV.
+type BulkCSVProvider struct {
+	// URL of the cache file. It may be gzipped or not.
+	URL string
+	// Client makes the requests. Its BaseURL is not used.
+	Client Client
+}
+
+func (p BulkCSVProvider) GetMETARs(ctx context.Context, airportIDs ...string) (map[string]METAR, error) {
+
+	if len(airportIDs) == 0 {
+		return nil, fmt.Errorf("no airport identifiers specified")
+	}
+
+	u := p.URL
+	if u == "" {
+		u = DefaultBulkCSVURL
+	}
+
+	bts, err := p.Client.fetch(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving METAR cache file: %w", err)
+	}
+
+	var r io.Reader = bytes.NewReader(bts)
+
+	if len(bts) > 1 && bts[0] == 0x1f && bts[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress METAR cache file: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	all, err := DecodeCSV(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode METAR cache file: %w", err)
+	}
+
+	return filterMETARs(all, airportIDs), nil
+}
+
+func filterMETARs(metars []METAR, airportIDs []string) map[string]METAR {
+	want := make(map[string]struct{}, len(airportIDs))
+	for _, id := range airportIDs {
+		want[id] = struct{}{}
+	}
+
+	out := make(map[string]METAR, len(airportIDs))
+	for _, m := range metars {
+		if _, ok := want[m.ICAOID]; !ok {
+			continue
+		}
+		latest(out, m)
+	}
+	return out
+}
@@ -0,0 +1,142 @@
+package metar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCachePath   = "/var/lib/metar-ws2811/metars.json"
+	DefaultCacheMaxAge = 2 * time.Hour
+)
+
+// Cache keeps the last good METAR for each airport on disk. It is safe for
+// concurrent use.
+type Cache struct {
+	Path string
+	// MaxAge is how old a cached METAR's observation can be and still be
+	// served. Zero uses DefaultCacheMaxAge.
+	MaxAge time.Duration
+
+	mu     sync.Mutex
+	metars map[string]METAR
+}
+
+func (c *Cache) path() string {
+	if c.Path != "" {
+		return c.Path
+	}
+	return DefaultCachePath
+}
+
+func (c *Cache) maxAge() time.Duration {
+	if c.MaxAge > 0 {
+		return c.MaxAge
+	}
+	return DefaultCacheMaxAge
+}
+
+// Load reads the cache file. A missing file is not an error.
+func (c *Cache) Load() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	bts, err := os.ReadFile(c.path())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	var metars map[string]METAR
+	if err := json.Unmarshal(bts, &metars); err != nil {
+		return fmt.Errorf("failed to unmarshal cache: %w", err)
+	}
+
+	c.metars = metars
+
+	return nil
+}
+
+// Update stores metars, replacing older cached observations for the same
+// airports, and writes the cache file.
+func (c *Cache) Update(metars map[string]METAR) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.metars == nil {
+		c.metars = make(map[string]METAR, len(metars))
+	}
+
+	for id, m := range metars {
+		if prev, ok := c.metars[id]; ok && time.Time(prev.ObservationTime).After(time.Time(m.ObservationTime)) {
+			continue
+		}
+		c.metars[id] = m
+	}
+
+	return c.save()
+}
+
+func (c *Cache) save() error {
+	bts, err := json.Marshal(c.metars)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache: %w", err)
+	}
+
+	pth := c.path()
+
+	if err := os.MkdirAll(filepath.Dir(pth), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp := pth + ".tmp"
+
+	if err := os.WriteFile(tmp, bts, 0o644); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	if err := os.Rename(tmp, pth); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+
+	return nil
+}
+
+// Fill adds any of airportIDs missing from metars from the cache and
+// returns the airports it filled.
+func (c *Cache) Fill(metars map[string]METAR, airportIDs []string, now time.Time) (map[string]METAR, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make(map[string]METAR, len(airportIDs))
+	for id, m := range metars {
+		out[id] = m
+	}
+
+	var filled []string
+
+	for _, id := range airportIDs {
+		if _, ok := out[id]; ok {
+			continue
+		}
+		m, ok := c.metars[id]
+		if !ok {
+			continue
+		}
+		if now.Sub(time.Time(m.ObservationTime)) > c.maxAge() {
+			continue
+		}
+		out[id] = m
+		filled = append(filled, id)
+	}
+
+	return out, filled
+}
@@ -0,0 +1,99 @@
+package metar_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+)
+
+func TestCacheFill(t *testing.T) {
+
+	pth := filepath.Join(t.TempDir(), "cache", "metars.json")
+
+	obs := time.Date(2024, 4, 14, 13, 52, 0, 0, time.UTC)
+
+	wx, err := metar.ParseRawAt("KFIT 141352Z AUTO 26010G15KT 10SM OVC020 12/M01 A2985", obs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := &metar.Cache{Path: pth, MaxAge: time.Hour}
+	if err := c.Update(map[string]metar.METAR{"KFIT": wx}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded := &metar.Cache{Path: pth, MaxAge: time.Hour}
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ids := []string{"KFIT", "KBOS"}
+
+	out, filled := loaded.Fill(nil, ids, obs.Add(30*time.Minute))
+	if len(filled) != 1 || filled[0] != "KFIT" {
+		t.Fatalf("expected KFIT filled, got %v", filled)
+	}
+	if cat := out["KFIT"].FlightCategory(); cat != metar.FlightCategoryMVFR {
+		t.Fatalf("expected %v, got %v", metar.FlightCategoryMVFR, cat)
+	}
+	if !time.Time(out["KFIT"].ObservationTime).Equal(obs) {
+		t.Fatalf("expected observation time %v, got %v", obs, time.Time(out["KFIT"].ObservationTime))
+	}
+
+	if _, filled := loaded.Fill(nil, ids, obs.Add(2*time.Hour)); len(filled) != 0 {
+		t.Fatalf("expected nothing filled after max age, got %v", filled)
+	}
+
+	fresh := map[string]metar.METAR{"KFIT": {ICAOID: "KFIT"}}
+	if out, filled := loaded.Fill(fresh, ids, obs); len(filled) != 0 || out["KFIT"].Visibility != nil {
+		t.Fatalf("expected fetched METAR kept, got %v", filled)
+	}
+}
+
+func TestCacheLoadMissing(t *testing.T) {
+	c := &metar.Cache{Path: filepath.Join(t.TempDir(), "missing.json")}
+	if err := c.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestColorServerServesCacheOnError(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<html>unavailable</html>", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	srv := &metar.ColorServer{
+		AirportIDs:          []string{"CYYL"},
+		LEDIndexByAirportID: map[string]int{"CYYL": 0},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		Cache: &metar.Cache{
+			Path:   filepath.Join(t.TempDir(), "metars.json"),
+			MaxAge: 100000 * time.Hour,
+		},
+	}
+
+	if _, err := srv.GetMETARs(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv.Client.BaseURL = down.URL
+
+	sts, err := srv.GetMETARs(context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if sts[0].FlightCategory != metar.FlightCategoryIFR {
+		t.Fatalf("expected %v, got %v", metar.FlightCategoryIFR, sts[0])
+	}
+}
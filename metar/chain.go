@@ -0,0 +1,262 @@
+package metar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMinCoverage = 0.5
+	DefaultDemote      = 5 * time.Minute
+)
+
+// ErrTooFewStations is recorded against a provider that returns fewer than
+// the minimum coverage of the airports requested from it.
+var ErrTooFewStations = errors.New("too few stations")
+
+// ChainLink is a named provider in a Chain.
+type ChainLink struct {
+	Name     string
+	Provider Provider
+}
+
+// ProviderHealth is the health of a provider in a Chain. A call fails when
+// it returns an error or too few stations.
+type ProviderHealth struct {
+	Name                string
+	Requests            int
+	Failures            int
+	ConsecutiveFailures int
+	Latency             time.Duration
+	AverageLatency      time.Duration
+	Stations            int
+	LastError           error
+	LastSuccess         time.Time
+	LastFailure         time.Time
+}
+
+// Healthy reports whether the last call to the provider succeeded.
+func (h ProviderHealth) Healthy() bool {
+	return h.Requests > 0 && h.ConsecutiveFailures == 0
+}
+
+// Chain is a Provider that requests the airports each provider fails to
+// supply from the next.
+type Chain struct {
+	Logger    *slog.Logger
+	Providers []ChainLink
+	// MinCoverage is the fraction of requested airports a provider must
+	// supply for the call to count as healthy. Zero uses DefaultMinCoverage.
+	MinCoverage float64
+	// Demote is how long a provider whose last call failed is tried after
+	// the others. Zero uses DefaultDemote.
+	Demote time.Duration
+
+	mu     sync.Mutex
+	health map[string]*ProviderHealth
+}
+
+func (c *Chain) log(f func(l *slog.Logger)) {
+	if c.Logger == nil {
+		return
+	}
+	f(c.Logger.With("pkg", "metar", "func", "Chain.GetMETARs"))
+}
+
+func (c *Chain) minCoverage() float64 {
+	if c.MinCoverage > 0 {
+		return c.MinCoverage
+	}
+	return DefaultMinCoverage
+}
+
+func (c *Chain) demote() time.Duration {
+	if c.Demote > 0 {
+		return c.Demote
+	}
+	return DefaultDemote
+}
+
+// Health returns the health of each provider in the chain, in order.
+// Providers that have not been called have zero Requests.
+func (c *Chain) Health() []ProviderHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make([]ProviderHealth, len(c.Providers))
+	for i, lnk := range c.Providers {
+		out[i] = ProviderHealth{Name: lnk.Name}
+		if h, ok := c.health[lnk.Name]; ok {
+			out[i] = *h
+		}
+	}
+	return out
+}
+
+// order moves providers that failed within Demote to the end.
+func (c *Chain) order(now time.Time) []ChainLink {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make([]ChainLink, 0, len(c.Providers))
+	var demoted []ChainLink
+	for _, lnk := range c.Providers {
+		if h, ok := c.health[lnk.Name]; ok && !h.Healthy() && now.Sub(h.LastFailure) < c.demote() {
+			demoted = append(demoted, lnk)
+			continue
+		}
+		out = append(out, lnk)
+	}
+	return append(out, demoted...)
+}
+
+// record updates the health of a provider and returns the call's error.
+func (c *Chain) record(name string, requested, supplied int, latency time.Duration, err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.health == nil {
+		c.health = make(map[string]*ProviderHealth)
+	}
+	h, ok := c.health[name]
+	if !ok {
+		h = &ProviderHealth{Name: name}
+		c.health[name] = h
+	}
+
+	if err == nil && float64(supplied) < c.minCoverage()*float64(requested) {
+		err = fmt.Errorf("%w: %d of %d", ErrTooFewStations, supplied, requested)
+	}
+
+	h.Requests++
+	h.Latency = latency
+	if h.AverageLatency == 0 {
+		h.AverageLatency = latency
+	} else {
+		h.AverageLatency = (h.AverageLatency*3 + latency) / 4
+	}
+	h.Stations = supplied
+	h.LastError = err
+
+	now := time.Now()
+	if err != nil {
+		h.Failures++
+		h.ConsecutiveFailures++
+		h.LastFailure = now
+		return err
+	}
+	h.ConsecutiveFailures = 0
+	h.LastSuccess = now
+
+	return nil
+}
+
+// GetMETARs gets METARs from each provider until every airport has one. The
+// error is nil when every airport was supplied.
+func (c *Chain) GetMETARs(ctx context.Context, airportIDs ...string) (map[string]METAR, error) {
+
+	if len(airportIDs) == 0 {
+		return nil, fmt.Errorf("no airport identifiers specified")
+	}
+
+	var (
+		out     = make(map[string]METAR, len(airportIDs))
+		sources = make(map[string]string, len(airportIDs))
+		missing = airportIDs
+		errs    []error
+	)
+
+	links := c.order(time.Now())
+
+	for i, lnk := range links {
+		if len(missing) == 0 {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		lctx, cancel := linkContext(ctx, len(links)-i)
+		start := time.Now()
+		metars, err := lnk.Provider.GetMETARs(lctx, missing...)
+		latency := time.Since(start)
+		cancel()
+
+		supplied := 0
+		for _, id := range missing {
+			m, ok := metars[id]
+			if !ok {
+				continue
+			}
+			out[id] = m
+			sources[id] = lnk.Name
+			supplied++
+		}
+
+		herr := c.record(lnk.Name, len(missing), supplied, latency, err)
+
+		c.log(func(l *slog.Logger) {
+			args := []any{"provider", lnk.Name, "requested", len(missing), "supplied", supplied, "latency", latency}
+			if herr != nil {
+				l.Warn("provider unhealthy", append(args, "error", herr)...)
+				return
+			}
+			l.Info("provider succeeded", args...)
+		})
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", lnk.Name, err))
+		}
+
+		missing = missingIDs(missing, out)
+	}
+
+	c.log(func(l *slog.Logger) {
+		byProvider := make(map[string][]string, len(c.Providers))
+		for id, name := range sources {
+			byProvider[name] = append(byProvider[name], id)
+		}
+		var args []any
+		for _, lnk := range c.Providers {
+			if ids, ok := byProvider[lnk.Name]; ok {
+				sort.Strings(ids)
+				args = append(args, lnk.Name, ids)
+			}
+		}
+		l.Info("METAR sources", args...)
+		if len(missing) > 0 {
+			l.Warn("no provider supplied METARs", "airports", missing)
+		}
+	})
+
+	if len(missing) == 0 {
+		return out, nil
+	}
+
+	return out, errors.Join(errs...)
+}
+
+// linkContext limits a provider to its share of the time left on ctx.
+func linkContext(ctx context.Context, remaining int) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || remaining <= 1 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Until(deadline)/time.Duration(remaining))
+}
+
+func missingIDs(ids []string, metars map[string]METAR) []string {
+	var out []string
+	for _, id := range ids {
+		if _, ok := metars[id]; !ok {
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,201 @@
+package metar_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+)
+
+func stubProvider(have ...string) (metar.Provider, *[][]string) {
+	var calls [][]string
+	return metar.ProviderFunc(func(ctx context.Context, airportIDs ...string) (map[string]metar.METAR, error) {
+		calls = append(calls, append([]string(nil), airportIDs...))
+		out := make(map[string]metar.METAR)
+		for _, id := range airportIDs {
+			for _, h := range have {
+				if id == h {
+					out[id] = metar.METAR{ICAOID: id}
+				}
+			}
+		}
+		return out, nil
+	}), &calls
+}
+
+func TestChain(t *testing.T) {
+
+	failing := metar.ProviderFunc(func(ctx context.Context, airportIDs ...string) (map[string]metar.METAR, error) {
+		return nil, errors.New("unavailable")
+	})
+	secondary, secondaryCalls := stubProvider("KBOS", "KJFK")
+	tertiary, tertiaryCalls := stubProvider("KJFK", "KSFO")
+
+	c := &metar.Chain{
+		Providers: []metar.ChainLink{
+			{Name: "primary", Provider: failing},
+			{Name: "secondary", Provider: secondary},
+			{Name: "tertiary", Provider: tertiary},
+		},
+	}
+
+	metars, err := c.GetMETARs(context.Background(), "KBOS", "KJFK", "KSFO")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp, got := []string{"KBOS", "KJFK", "KSFO"}, sortedIDs(metars); strings.Join(exp, ",") != strings.Join(got, ",") {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	// Later providers are only asked for airports still missing.
+	if exp, got := "KBOS,KJFK,KSFO", strings.Join((*secondaryCalls)[0], ","); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	if exp, got := "KSFO", strings.Join((*tertiaryCalls)[0], ","); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	health := c.Health()
+	if len(health) != 3 {
+		t.Fatalf("expected %v, got %v", 3, len(health))
+	}
+	if health[0].Healthy() || health[0].Failures != 1 || health[0].LastError == nil {
+		t.Fatalf("expected primary unhealthy, got %+v", health[0])
+	}
+	if !health[1].Healthy() || health[1].Stations != 2 {
+		t.Fatalf("expected secondary healthy with 2 stations, got %+v", health[1])
+	}
+	if !health[2].Healthy() || health[2].Stations != 1 {
+		t.Fatalf("expected tertiary healthy with 1 station, got %+v", health[2])
+	}
+}
+
+func TestChainTooFewStations(t *testing.T) {
+
+	primary, _ := stubProvider("KBOS")
+	secondary, secondaryCalls := stubProvider()
+
+	c := &metar.Chain{
+		Providers: []metar.ChainLink{
+			{Name: "primary", Provider: primary},
+			{Name: "secondary", Provider: secondary},
+		},
+		MinCoverage: 0.5,
+	}
+
+	metars, err := c.GetMETARs(context.Background(), "KBOS", "KJFK", "KSFO")
+	if err != nil {
+		t.Fatalf("expected no error when no provider failed, got %v", err)
+	}
+	if len(metars) != 1 {
+		t.Fatalf("expected %v, got %v", 1, len(metars))
+	}
+	if len(*secondaryCalls) != 1 {
+		t.Fatalf("expected %v, got %v", 1, len(*secondaryCalls))
+	}
+
+	health := c.Health()
+	if !errors.Is(health[0].LastError, metar.ErrTooFewStations) {
+		t.Fatalf("expected %v, got %v", metar.ErrTooFewStations, health[0].LastError)
+	}
+}
+
+func TestChainAllFail(t *testing.T) {
+
+	failing := metar.ProviderFunc(func(ctx context.Context, airportIDs ...string) (map[string]metar.METAR, error) {
+		return nil, errors.New("unavailable")
+	})
+
+	c := &metar.Chain{
+		Providers: []metar.ChainLink{
+			{Name: "primary", Provider: failing},
+			{Name: "secondary", Provider: failing},
+		},
+	}
+
+	if _, err := c.GetMETARs(context.Background(), "KBOS"); err == nil || !strings.Contains(err.Error(), "secondary") {
+		t.Fatalf("expected error from each provider, got %v", err)
+	}
+
+	for _, h := range c.Health() {
+		if h.Healthy() || h.ConsecutiveFailures != 1 {
+			t.Fatalf("expected %v unhealthy, got %+v", h.Name, h)
+		}
+	}
+}
+
+func TestChainTimeout(t *testing.T) {
+
+	blocking := metar.ProviderFunc(func(ctx context.Context, airportIDs ...string) (map[string]metar.METAR, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	secondary, _ := stubProvider("KBOS", "KJFK")
+
+	c := &metar.Chain{
+		Providers: []metar.ChainLink{
+			{Name: "primary", Provider: blocking},
+			{Name: "secondary", Provider: secondary},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	metars, err := c.GetMETARs(ctx, "KBOS", "KJFK")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp, got := []string{"KBOS", "KJFK"}, sortedIDs(metars); strings.Join(exp, ",") != strings.Join(got, ",") {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if health := c.Health(); !errors.Is(health[0].LastError, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, health[0].LastError)
+	}
+}
+
+func TestChainDemote(t *testing.T) {
+
+	var primaryCalls int
+	failing := metar.ProviderFunc(func(ctx context.Context, airportIDs ...string) (map[string]metar.METAR, error) {
+		primaryCalls++
+		return nil, errors.New("unavailable")
+	})
+	secondary, secondaryCalls := stubProvider("KBOS")
+
+	c := &metar.Chain{
+		Providers: []metar.ChainLink{
+			{Name: "primary", Provider: failing},
+			{Name: "secondary", Provider: secondary},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetMETARs(context.Background(), "KBOS"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The failed primary is tried after the secondary, which has every airport.
+	if primaryCalls != 1 {
+		t.Fatalf("expected %v, got %v", 1, primaryCalls)
+	}
+	if len(*secondaryCalls) != 2 {
+		t.Fatalf("expected %v, got %v", 2, len(*secondaryCalls))
+	}
+
+	c.Demote = time.Nanosecond
+
+	if _, err := c.GetMETARs(context.Background(), "KBOS"); err != nil {
+		t.Fatal(err)
+	}
+	if primaryCalls != 2 {
+		t.Fatalf("expected %v, got %v", 2, primaryCalls)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/andrewmostello/metar-ws2811/ws2811"
@@ -53,6 +54,46 @@ func FlightCategoryToRGB(colors map[FlightCategory]ws2811.RGB, cats map[int]Flig
 	return out
 }
 
+// Mode determines what the ColorServer displays.
+type Mode string
+
+const (
+	// ModeFlightCategory displays the current flight category of each airport.
+	ModeFlightCategory Mode = "flight_category"
+	// ModeForecast loops through the current and forecast flight categories
+	// of each airport.
+	ModeForecast Mode = "forecast"
+	// ModeTemperature displays the temperature of each airport through a
+	// Gradient.
+	ModeTemperature Mode = "temperature"
+	// ModeCeilingVisibility displays the lower of the normalized ceiling and
+	// visibility of each airport through a Gradient.
+	ModeCeilingVisibility Mode = "ceiling_visibility"
+	// ModeWind displays the wind speed of each airport through a Gradient,
+	// with a surge on gusting airports and a slow pulse on variable wind.
+	ModeWind Mode = "wind"
+	// ModeCrosswind displays the worst-case crosswind of each airport through
+	// a Gradient. It requires Runways.
+	ModeCrosswind Mode = "crosswind"
+	// ModeDensityAltitude displays the density altitude of each airport
+	// through a Gradient.
+	ModeDensityAltitude Mode = "density_altitude"
+	// ModeFog displays the temperature/dewpoint spread of each airport
+	// through a Gradient, projected ahead when it is shrinking. Projection
+	// needs a provider of earlier METARs, such as the Client.
+	ModeFog Mode = "fog"
+)
+
+// IsGradient reports whether the mode displays a value of each airport
+// through a Gradient rather than its flight category.
+func (md Mode) IsGradient() bool {
+	switch md {
+	case ModeTemperature, ModeCeilingVisibility, ModeWind, ModeCrosswind, ModeDensityAltitude, ModeFog:
+		return true
+	}
+	return false
+}
+
 type ColorServer struct {
 	Logger              *slog.Logger
 	Colors              map[FlightCategory]ws2811.RGB
@@ -60,6 +101,54 @@ type ColorServer struct {
 	LEDIndexByAirportID map[string]int
 	Timeout             time.Duration
 	Client              Client
+	// Provider, if set, is used for METARs instead of Client. Client is
+	// still used for TAFs.
+	Provider Provider
+	Mode     Mode
+	Forecast ForecastOptions
+	Fog      FogOptions
+	// Gradient maps values to colors in gradient modes. Nil uses the default
+	// gradient of the mode.
+	Gradient Gradient
+	// Runways are the runways of each airport, used for crosswinds.
+	Runways Runways
+	// Rules determine flight categories. Nil uses FAARules.
+	Rules *Rules
+	// Minimums, if set, are personal minimums.
+	Minimums      *Minimums
+	MinimumsColor *ws2811.RGB
+	// Lightning, if set, flashes airports reporting lightning.
+	Lightning *LightningOptions
+	// Wind, if set, blinks or pulses airports with high wind.
+	Wind *WindOptions
+	// DensityAltitude, if set, swells airports with high density altitude
+	// toward a warning color.
+	DensityAltitude *DensityAltitudeOptions
+	// Attention, if set, pulses airports whose flight category changed at
+	// the last refresh.
+	Attention *AttentionOptions
+	// Cache, if set, serves the last good METARs when a refresh fails.
+	Cache *Cache
+	// StaleAfter is how old an observation can be before it is displayed as
+	// stale. Zero uses DefaultStaleAfter.
+	StaleAfter time.Duration
+	// StaleColor, if set, is displayed for stale airports instead of
+	// dimming them.
+	StaleColor *ws2811.RGB
+	// StaleDim is the brightness of stale airports relative to their flight
+	// category color. Zero uses DefaultStaleDim.
+	StaleDim float64
+	// MissingColor is displayed for airports without an observation. Nil
+	// uses DefaultMissingColor.
+	MissingColor *ws2811.RGB
+
+	// categories are the flight categories of the previous refresh.
+	categories map[int]FlightCategory
+	// previous are the observations before the current ones in ModeFog.
+	previous map[string]METAR
+	// mu guards published.
+	mu        sync.Mutex
+	published map[int]Status
 }
 
 func (srv *ColorServer) log(f func(l *slog.Logger)) {
@@ -73,6 +162,13 @@ func (srv *ColorServer) FlightCategoryToRGB(cats map[int]FlightCategory) map[int
 	return FlightCategoryToRGB(srv.Colors, cats)
 }
 
+func (srv *ColorServer) provider() Provider {
+	if srv.Provider != nil {
+		return srv.Provider
+	}
+	return srv.Client
+}
+
 func (srv *ColorServer) timeout() time.Duration {
 	if srv.Timeout > 0 {
 		return srv.Timeout
@@ -80,28 +176,65 @@ func (srv *ColorServer) timeout() time.Duration {
 	return 15 * time.Second
 }
 
-func (srv *ColorServer) GetMETARs(ctx context.Context) (map[int]FlightCategory, error) {
+func (srv *ColorServer) GetMETARs(ctx context.Context) (map[int]Status, error) {
 
 	to := srv.timeout()
 
-	ctx, cancel := context.WithTimeout(ctx, to)
+	fctx, cancel := context.WithTimeout(ctx, to)
 	defer cancel()
 
 	srv.log(func(l *slog.Logger) {
 		l.Info("getting METARs", "timeout", to)
 	})
 
-	metars, err := srv.Client.GetMETARs(ctx, srv.AirportIDs...)
+	metars, err := srv.fetchMETARs(fctx)
+
+	if srv.Mode == ModeFog && len(metars) > 0 {
+		srv.previous = srv.fetchPrevious(ctx, metars)
+	}
+
+	return srv.statuses(metars, time.Now()), err
+}
+
+// fetchMETARs gets the METARs for every airport, filling any missing from
+// the Cache.
+func (srv *ColorServer) fetchMETARs(ctx context.Context) (map[string]METAR, error) {
+
+	metars, err := srv.provider().GetMETARs(ctx, srv.AirportIDs...)
 	if err != nil {
 		srv.log(func(l *slog.Logger) {
-			l.Error("failed to get METARs", "error", err)
+			l.Error("failed to get METARs", "error", err, "received", len(metars))
+		})
+		err = fmt.Errorf("failed to get METARs: %w", err)
+	}
+
+	if srv.Cache != nil && len(metars) > 0 {
+		if err := srv.Cache.Update(metars); err != nil {
+			srv.log(func(l *slog.Logger) {
+				l.Warn("failed to update cache", "error", err)
+			})
+		}
+	}
+
+	if srv.Cache == nil {
+		return metars, err
+	}
+
+	metars, filled := srv.Cache.Fill(metars, srv.AirportIDs, time.Now())
+	if len(filled) > 0 {
+		srv.log(func(l *slog.Logger) {
+			l.Warn("using cached METARs", "airports", filled)
 		})
-		return nil, fmt.Errorf("failed to get METARs: %w", err)
 	}
 
+	return metars, err
+}
+
+func (srv *ColorServer) statuses(metars map[string]METAR, now time.Time) map[int]Status {
+
 	idxs := srv.LEDIndexByAirportID
 
-	fcs := make(map[int]FlightCategory, len(idxs))
+	sts := make(map[int]Status, len(idxs))
 
 	for id, wx := range metars {
 		idx, ok := idxs[id]
@@ -111,22 +244,165 @@ func (srv *ColorServer) GetMETARs(ctx context.Context) (map[int]FlightCategory,
 			})
 			continue
 		}
-		fc := wx.FlightCategory()
+		fc := srv.Rules.FlightCategory(wx)
+		below := srv.belowMinimums(wx, fc)
+		st := Status{
+			FlightCategory: fc,
+			BelowMinimums:  len(below) > 0,
+			Lightning:      wx.HasLightning(),
+			Stale:          wx.IsStale(now, srv.staleAfter()),
+		}
+		if srv.DensityAltitude != nil {
+			st.HighDensityAltitude = srv.DensityAltitude.High(wx)
+		}
+		if v, ok := srv.gradientValue(wx); ok {
+			st.Value = v
+			st.HasValue = true
+		}
+		if srv.Mode == ModeWind {
+			st.GustFactor = wx.GustFactor()
+			st.VariableWind = wx.HasVariableWind()
+		}
+		if srv.Wind != nil && wx.IsHighWind(srv.Wind.threshold()) {
+			st.HighWind = true
+			st.GustFactor = wx.GustFactor()
+		}
 		srv.log(func(l *slog.Logger) {
-			l.Info("METAR", "airport", id, "index", idx, "flightCategory", fc.Name(), "weather", wx.RawObservation)
+			l.Info("METAR", "airport", id, "index", idx, "flightCategory", st.FlightCategory.Name(), "value", st.Value, "belowMinimums", below, "stale", st.Stale, "weather", wx.RawObservation)
 		})
-		fcs[idx] = fc
+		sts[idx] = st
 	}
 
-	return fcs, nil
+	for _, id := range srv.AirportIDs {
+		idx, ok := idxs[id]
+		if !ok {
+			continue
+		}
+		if _, ok := metars[id]; ok {
+			continue
+		}
+		srv.log(func(l *slog.Logger) {
+			l.Warn("no METAR for airport", "airport", id, "index", idx)
+		})
+		sts[idx] = Status{Missing: true}
+	}
+
+	return sts
 }
 
-func (srv *ColorServer) Serve(ctx context.Context, scd cron.Schedule, output chan (map[int]ws2811.RGB)) error {
+// frame is a set of LED effects to display for a duration.
+type frame struct {
+	state ws2811.State
+	dur   time.Duration
+}
+
+func (srv *ColorServer) frames(ctx context.Context) []frame {
+
+	if srv.Mode == ModeForecast {
+		now := time.Now()
+		sts, hours, err := srv.forecast(ctx, now)
+		if err != nil {
+			srv.log(func(l *slog.Logger) {
+				l.Error("failed refresh", "error", err)
+			})
+		}
+
+		srv.publish(sts)
+
+		if sts == nil {
+			return []frame{{state: ws2811.StaticState(srv.FlightCategoryToRGB(nil))}}
+		}
+
+		state := srv.StatusToState(sts)
+		srv.attention(now, sts, state)
+
+		missing := make(map[int]Status)
+		for idx, st := range sts {
+			if st.Missing {
+				missing[idx] = st
+			}
+		}
+
+		return srv.forecastFrames(state, srv.StatusToRGB(missing), hours)
+	}
+
+	sts, err := srv.GetMETARs(ctx)
+	if err != nil {
+		srv.log(func(l *slog.Logger) {
+			l.Error("failed refresh", "error", err)
+		})
+	}
+
+	srv.publish(sts)
+
+	state := srv.StatusToState(sts)
+	srv.attention(time.Now(), sts, state)
+
+	return []frame{{state: state}}
+}
+
+func (srv *ColorServer) publish(sts map[int]Status) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.published = sts
+}
+
+// LEDStatus returns the status of the airport displayed on an LED at the last
+// refresh.
+func (srv *ColorServer) LEDStatus(index int) (Status, bool) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	st, ok := srv.published[index]
+	return st, ok
+}
+
+// play loops frames to output until done. It returns false if ctx was closed.
+func (srv *ColorServer) play(ctx context.Context, done <-chan time.Time, frames []frame, output chan ws2811.State) bool {
+	for i := 0; ; i = (i + 1) % len(frames) {
+		select {
+		case output <- frames[i].state:
+		case <-done:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+
+		if len(frames) == 1 {
+			select {
+			case <-done:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		t := time.NewTimer(frames[i].dur)
+		select {
+		case <-t.C:
+		case <-done:
+			t.Stop()
+			return true
+		case <-ctx.Done():
+			t.Stop()
+			return false
+		}
+	}
+}
+
+func (srv *ColorServer) Serve(ctx context.Context, scd cron.Schedule, output chan ws2811.State) error {
 
 	srv.log(func(l *slog.Logger) {
 		l.Info("serving METARs", "airports", srv.AirportIDs)
 	})
 
+	if srv.Cache != nil {
+		if err := srv.Cache.Load(); err != nil {
+			srv.log(func(l *slog.Logger) {
+				l.Warn("failed to load cache", "error", err)
+			})
+		}
+	}
+
 	defer func() {
 		srv.log(func(l *slog.Logger) {
 			l.Info("stopped serving METARs")
@@ -134,14 +410,7 @@ func (srv *ColorServer) Serve(ctx context.Context, scd cron.Schedule, output cha
 	}()
 
 	for {
-		fcs, err := srv.GetMETARs(ctx)
-		if err != nil {
-			srv.log(func(l *slog.Logger) {
-				l.Error("failed refresh", "error", err)
-			})
-		}
-
-		output <- srv.FlightCategoryToRGB(fcs)
+		frames := srv.frames(ctx)
 
 		nxt := scd.Next(time.Now())
 
@@ -151,17 +420,11 @@ func (srv *ColorServer) Serve(ctx context.Context, scd cron.Schedule, output cha
 
 		t := time.NewTimer(time.Until(nxt))
 
-		select {
-		case <-t.C:
-			continue
-
-		case <-ctx.Done():
+		if !srv.play(ctx, t.C, frames, output) {
 			srv.log(func(l *slog.Logger) {
 				l.Info("stopping")
 			})
-			if !t.Stop() {
-				<-t.C
-			}
+			t.Stop()
 			return nil
 		}
 	}
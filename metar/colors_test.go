@@ -0,0 +1,189 @@
+package metar_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+	"github.com/robfig/cron/v3"
+	ws281x "github.com/rpi-ws281x/rpi-ws281x-go"
+)
+
+// newTestdataServer serves METARs from testdata for the requested ids, and
+// the KBOS TAF.
+func newTestdataServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	bts, err := os.ReadFile("testdata/2024-04-14.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var all []json.RawMessage
+	if err := json.Unmarshal(bts, &all); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byID := make(map[string]json.RawMessage, len(all))
+	for _, raw := range all {
+		var m struct {
+			ICAOID string `json:"icaoId"`
+		}
+		if err := json.Unmarshal(raw, &m); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		byID[m.ICAOID] = raw
+	}
+
+	tafs, err := os.ReadFile("testdata/taf-2024-04-14.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/taf" {
+			w.Write(tafs)
+			return
+		}
+		out := []json.RawMessage{}
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			if raw, ok := byID[id]; ok {
+				out = append(out, raw)
+			}
+		}
+		w.Header().Set("content-type", "application/json")
+		json.NewEncoder(w).Encode(out)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestColorServerPipeline(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"CYYL", "KBOK", "PAOU", "CYLU"},
+		LEDIndexByAirportID: map[string]int{
+			"CYYL": 0,
+			"KBOK": 1,
+			"PAOU": 2,
+			"CYLU": 3,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: 100000 * time.Hour,
+	}
+
+	rendered := make(chan []ws2811.RGB, 16)
+
+	ctrl := &ws2811.Controller{
+		Driver: &ws2811.Simulator{
+			OnRender: func(frame []ws2811.RGB) {
+				rendered <- frame
+			},
+		},
+		Options: []ws2811.Option{
+			func(opt *ws281x.ChannelOption) {
+				opt.LedCount = 5
+			},
+		},
+	}
+
+	scd, err := cron.ParseStandard("0 0 1 1 *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leds := make(chan ws2811.State)
+
+	go srv.Serve(ctx, scd, leds)
+	go ctrl.Serve(ctx, leds)
+
+	select {
+	case frame := <-rendered:
+		exp := []ws2811.RGB{
+			metar.DefaultColors[metar.FlightCategoryIFR],
+			metar.DefaultColors[metar.FlightCategoryLIFR],
+			metar.DefaultColors[metar.FlightCategoryVFR],
+			// CYLU's VV011 is an 1100 ft ceiling at 1 SM.
+			metar.DefaultColors[metar.FlightCategoryIFR],
+			ws2811.Off,
+		}
+		for i := range exp {
+			if frame[i] != exp[i] {
+				t.Fatalf("expected %v at %d, got %v", exp[i], i, frame[i])
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for frame")
+	}
+}
+
+func TestColorServerLEDStatus(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"KCGS", "KAVP", "KXXX"},
+		LEDIndexByAirportID: map[string]int{
+			"KCGS": 0,
+			"KAVP": 1,
+			"KXXX": 2,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: 100000 * time.Hour,
+		Lightning:  &metar.LightningOptions{},
+	}
+
+	if _, ok := srv.LEDStatus(0); ok {
+		t.Fatal("expected no status before the first refresh")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leds := make(chan ws2811.State)
+
+	go srv.Serve(ctx, everySchedule(time.Hour), leds)
+
+	select {
+	case <-leds:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for state")
+	}
+
+	exp := map[int]metar.FlightCategory{
+		0: metar.FlightCategoryVFR,
+		// Flashing for lightning.
+		1: metar.FlightCategoryIFR,
+	}
+
+	for idx, fc := range exp {
+		if st, ok := srv.LEDStatus(idx); !ok || st.FlightCategory != fc {
+			t.Fatalf("expected %v at %d, got %v (%v)", fc, idx, st, ok)
+		}
+	}
+
+	if st, ok := srv.LEDStatus(2); !ok || !st.Missing {
+		t.Fatalf("expected missing at 2, got %v (%v)", st, ok)
+	}
+
+	if _, ok := srv.LEDStatus(3); ok {
+		t.Fatal("expected no status without an airport")
+	}
+}
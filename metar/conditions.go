@@ -0,0 +1,65 @@
+package metar
+
+import (
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+var (
+	// ceilingScale and visibilityScale are normalized to 0, 1, 2, 3 and 4.
+	ceilingScale    = []float64{0, 500, 1000, 3000, 12000}
+	visibilityScale = []float64{0, 1, 3, 5, 10}
+
+	// DefaultConditionsGradient runs through the flight category colors, and
+	// from cyan at the MVFR limit to green at 12,000 ft and 10 SM.
+	DefaultConditionsGradient = Gradient{
+		{Value: 0, Color: ws2811.RGB{Red: 255, Green: 0, Blue: 255}},
+		{Value: 1, Color: ws2811.RGB{Red: 255, Green: 0, Blue: 0}},
+		{Value: 2, Color: ws2811.RGB{Red: 0, Green: 0, Blue: 255}},
+		{Value: 3, Color: ws2811.RGB{Red: 0, Green: 255, Blue: 255}},
+		{Value: 4, Color: ws2811.RGB{Red: 0, Green: 255, Blue: 0}},
+	}
+)
+
+// normalize maps v between the points of scale to their index.
+func normalize(v float64, scale []float64) float64 {
+	if v <= scale[0] {
+		return 0
+	}
+	for i := 1; i < len(scale); i++ {
+		if v < scale[i] {
+			return float64(i-1) + (v-scale[i-1])/(scale[i]-scale[i-1])
+		}
+	}
+	return float64(len(scale) - 1)
+}
+
+// NormalizedConditions returns the lower of the normalized ceiling and
+// visibility, where 1, 2 and 3 are the LIFR, IFR and MVFR limits. It returns
+// false when visibility or the height of a ceiling layer is not reported.
+func (m METAR) NormalizedConditions() (float64, bool) {
+
+	vis := m.Visibility
+	// CAVOK implies visibility of 10 km or more.
+	if vis == nil && hasCover(m.Clouds, CloudCoverCAVOK) {
+		vis = &Visibility{Visibility: 6, GreaterThan: true}
+	}
+	if vis == nil {
+		return 0, false
+	}
+
+	out := normalize(vis.Visibility, visibilityScale)
+	// Visibilities above 6 SM are reported as P6SM.
+	if vis.GreaterThan {
+		out = normalize(visibilityScale[len(visibilityScale)-1], visibilityScale)
+	}
+
+	ceil, ok, known := ceiling(m.Clouds, m.VerticalVisibility)
+	if !known {
+		return 0, false
+	}
+	if ok {
+		out = min(out, normalize(ceil, ceilingScale))
+	}
+
+	return out, true
+}
@@ -0,0 +1,95 @@
+package metar_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+func TestNormalizedConditions(t *testing.T) {
+	type fixture struct {
+		raw string
+		exp float64
+		ok  bool
+	}
+
+	fixtures := []fixture{
+		{raw: "KFIT 141352Z 26010KT 10SM CLR 12/M01 A2985", exp: 4, ok: true},
+		{raw: "KFIT 141352Z 26010KT 10SM OVC120 12/M01 A2985", exp: 4, ok: true},
+		{raw: "KFIT 141352Z 26010KT 10SM OVC075 12/M01 A2985", exp: 3.5, ok: true},
+		// Just above the MVFR ceiling limit.
+		{raw: "KFIT 141352Z 26010KT 10SM OVC031 12/M01 A2985", exp: 3 + 100.0/9000, ok: true},
+		{raw: "KFIT 141352Z 26010KT 10SM SCT005 12/M01 A2985", exp: 4, ok: true},
+		// Visibility is lower than the 800 ft ceiling at 1.6.
+		{raw: "KFIT 141352Z 26010KT 2SM BR OVC008 12/11 A2985", exp: 1.5, ok: true},
+		{raw: "KFIT 141352Z 26010KT 1/2SM FG VV002 12/11 A2985", exp: 0.4, ok: true},
+		{raw: "KFIT 141352Z 26010KT P6SM SKC 12/M01 A2985", exp: 4, ok: true},
+		{raw: "EGLL 141350Z 26010KT CAVOK 12/M01 Q1013", exp: 4, ok: true},
+		{raw: "KFIT 141352Z 26010KT OVC031 12/M01 A2985"},
+	}
+
+	ref := time.Date(2024, 4, 14, 18, 0, 0, 0, time.UTC)
+
+	for _, fix := range fixtures {
+		t.Run(fix.raw, func(t *testing.T) {
+			m, err := metar.ParseRawAt(fix.raw, ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := m.NormalizedConditions()
+			if ok != fix.ok {
+				t.Fatalf("expected %v, got %v", fix.ok, ok)
+			}
+			if math.Abs(got-fix.exp) > 1e-9 {
+				t.Fatalf("expected %v, got %v", fix.exp, got)
+			}
+		})
+	}
+}
+
+func TestColorServerCeilingVisibility(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"PAOU", "KCLL", "KSGT"},
+		LEDIndexByAirportID: map[string]int{
+			"PAOU": 0,
+			"KCLL": 1,
+			"KSGT": 2,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: 100000 * time.Hour,
+		Mode:       metar.ModeCeilingVisibility,
+	}
+
+	sts, err := srv.GetMETARs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	g := metar.DefaultConditionsGradient
+
+	exp := map[int]ws2811.RGB{
+		// OVC038 is VFR, just above the MVFR limit.
+		0: g.Color(3 + 800.0/9000),
+		// OVC029 is MVFR.
+		1: g.Color(2 + 1900.0/2000),
+		// No visibility.
+		2: metar.DefaultColors[metar.FlightCategoryUnknown],
+	}
+
+	colors := srv.StatusToRGB(sts)
+
+	for idx, c := range exp {
+		if colors[idx] != c {
+			t.Fatalf("expected %v at %d, got %v", c, idx, colors[idx])
+		}
+	}
+}
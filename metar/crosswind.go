@@ -0,0 +1,70 @@
+package metar
+
+import (
+	"math"
+
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+var (
+	// DefaultCrosswindGradient is green up to 10 kt of crosswind, yellow up
+	// to 15 kt and red above. Repeated values make steps.
+	DefaultCrosswindGradient = Gradient{
+		{Value: 0, Color: ws2811.RGB{Red: 0, Green: 255, Blue: 0}},
+		{Value: 10, Color: ws2811.RGB{Red: 0, Green: 255, Blue: 0}},
+		{Value: 10, Color: ws2811.RGB{Red: 255, Green: 255, Blue: 0}},
+		{Value: 15, Color: ws2811.RGB{Red: 255, Green: 255, Blue: 0}},
+		{Value: 15, Color: ws2811.RGB{Red: 255, Green: 0, Blue: 0}},
+	}
+)
+
+// CrosswindComponent returns the crosswind component, in knots, of speed from
+// direction on a runway with heading, both in degrees.
+func CrosswindComponent(direction float64, speed float64, heading float64) float64 {
+	return math.Abs(speed * math.Sin((direction-heading)*math.Pi/180))
+}
+
+// Crosswind returns the worst-case crosswind, in knots, on the runway best
+// aligned with the wind. It returns false without runways.
+func (m METAR) Crosswind(runways []Runway) (float64, bool) {
+
+	if len(runways) == 0 {
+		return 0, false
+	}
+
+	speed := m.MaxWind()
+	if speed == 0 {
+		return 0, true
+	}
+	if m.WindDirection.Variable {
+		return speed, true
+	}
+
+	from, to := float64(m.WindDirection.From), float64(m.WindDirection.From)
+	if v := m.WindVariability; v != nil {
+		from, to = float64(v.From), float64(v.To)
+	}
+
+	out := math.Inf(1)
+	for _, rwy := range runways {
+		out = min(out, maxCrosswind(from, to, speed, rwy.Heading))
+	}
+
+	return out, true
+}
+
+// maxCrosswind returns the highest crosswind component from directions
+// clockwise from from to to.
+func maxCrosswind(from float64, to float64, speed float64, heading float64) float64 {
+
+	out := max(CrosswindComponent(from, speed, heading), CrosswindComponent(to, speed, heading))
+
+	arc := math.Mod(to-from+360, 360)
+	for _, perp := range []float64{heading + 90, heading + 270} {
+		if math.Mod(perp-from+720, 360) <= arc {
+			return speed
+		}
+	}
+
+	return out
+}
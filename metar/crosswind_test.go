@@ -0,0 +1,158 @@
+package metar_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+func TestLoadRunways(t *testing.T) {
+
+	exp := metar.Runways{
+		"KCGS": {{Ident: "15/33", Heading: 147}},
+		"KAVP": {{Ident: "04/22", Heading: 28.2}, {Ident: "10/28", Heading: 88.2}},
+		// Without a true heading, from the runway number.
+		"PAOU": {{Ident: "08/26", Heading: 80}},
+	}
+
+	for _, pth := range []string{"testdata/runways.csv", "testdata/runways.json"} {
+		t.Run(pth, func(t *testing.T) {
+			rwys, err := metar.LoadRunways(pth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rwys) != len(exp) {
+				t.Fatalf("expected %d airports, got %d", len(exp), len(rwys))
+			}
+			for id, exp := range exp {
+				got := rwys[id]
+				if len(got) != len(exp) {
+					t.Fatalf("expected %v for %s, got %v", exp, id, got)
+				}
+				for i := range exp {
+					if got[i] != exp[i] {
+						t.Fatalf("expected %v for %s, got %v", exp[i], id, got[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCrosswind(t *testing.T) {
+	type fixture struct {
+		name    string
+		raw     string
+		runways []metar.Runway
+		exp     float64
+		ok      bool
+	}
+
+	rwy15 := []metar.Runway{{Ident: "15/33", Heading: 147}}
+
+	fixtures := []fixture{
+		{name: "gusts", raw: "KCGS 141610Z 18008G19KT 10SM CLR 22/02 A2993", runways: rwy15, exp: 19 * math.Sin(33*math.Pi/180), ok: true},
+		{name: "calm", raw: "KCGS 141610Z 00000KT 10SM CLR 22/02 A2993", runways: rwy15, exp: 0, ok: true},
+		{name: "variable", raw: "KCGS 141610Z VRB05KT 10SM CLR 22/02 A2993", runways: rwy15, exp: 5, ok: true},
+		{name: "aligned", raw: "KCGS 141610Z 15010KT 10SM CLR 22/02 A2993", runways: rwy15, exp: 10 * math.Sin(3*math.Pi/180), ok: true},
+		{name: "reciprocal", raw: "KCGS 141610Z 33010KT 10SM CLR 22/02 A2993", runways: rwy15, exp: 10 * math.Sin(3*math.Pi/180), ok: true},
+		{name: "variable range across the runway", raw: "KCGS 141610Z 24010KT 200V280 10SM CLR 22/02 A2993", runways: rwy15, exp: 10, ok: true},
+		{name: "variable range", raw: "KCGS 141610Z 16010KT 150V180 10SM CLR 22/02 A2993", runways: rwy15, exp: 10 * math.Sin(33*math.Pi/180), ok: true},
+		{
+			name:    "best runway",
+			raw:     "KAVP 141608Z 09015KT 10SM CLR 11/05 A2978",
+			runways: []metar.Runway{{Ident: "04/22", Heading: 28.2}, {Ident: "10/28", Heading: 88.2}},
+			exp:     15 * math.Sin(1.8*math.Pi/180),
+			ok:      true,
+		},
+		{name: "no runways", raw: "KCGS 141610Z 18008G19KT 10SM CLR 22/02 A2993"},
+	}
+
+	ref := time.Date(2024, 4, 14, 18, 0, 0, 0, time.UTC)
+
+	for _, fix := range fixtures {
+		t.Run(fix.name, func(t *testing.T) {
+			m, err := metar.ParseRawAt(fix.raw, ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := m.Crosswind(fix.runways)
+			if ok != fix.ok {
+				t.Fatalf("expected %v, got %v", fix.ok, ok)
+			}
+			if math.Abs(got-fix.exp) > 1e-9 {
+				t.Fatalf("expected %v, got %v", fix.exp, got)
+			}
+		})
+	}
+}
+
+func TestColorServerCrosswind(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	rwys, err := metar.LoadRunways("testdata/runways.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"KCGS", "PAOU", "KAVP", "CYLU"},
+		LEDIndexByAirportID: map[string]int{
+			"KCGS": 0,
+			"PAOU": 1,
+			"KAVP": 2,
+			"CYLU": 3,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: 100000 * time.Hour,
+		Mode:       metar.ModeCrosswind,
+		Runways:    rwys,
+	}
+
+	sts, err := srv.GetMETARs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	green := ws2811.RGB{Green: 255}
+	yellow := ws2811.RGB{Red: 255, Green: 255}
+	red := ws2811.RGB{Red: 255}
+
+	exp := map[int]ws2811.RGB{
+		// 18008G19KT is 10.3 kt across runway 15.
+		0: yellow,
+		// 32010KT is 8.7 kt across runway 08.
+		1: green,
+		// VRB05G19KT
+		2: red,
+		// No runways.
+		3: metar.DefaultColors[metar.FlightCategoryUnknown],
+	}
+
+	colors := srv.StatusToRGB(sts)
+
+	for idx, c := range exp {
+		if colors[idx] != c {
+			t.Fatalf("expected %v at %d, got %v", c, idx, colors[idx])
+		}
+	}
+
+	// With runways, KCGS is within a 12 kt crosswind minimum.
+	srv.Mode = metar.ModeFlightCategory
+	srv.Minimums = &metar.Minimums{Crosswind: 12}
+
+	sts, err = srv.GetMETARs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sts[0].BelowMinimums {
+		t.Fatalf("expected KCGS within minimums, got %v", sts[0])
+	}
+}
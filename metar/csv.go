@@ -0,0 +1,95 @@
+package metar
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DecodeCSV decodes METARs in the ADDS CSV format. Lines before the header
+// and records that fail to decode are skipped.
+func DecodeCSV(r io.Reader) ([]METAR, error) {
+
+	br := bufio.NewReader(r)
+
+	for {
+		line, err := br.ReadString('\n')
+		if strings.HasPrefix(line, "raw_text,") {
+			return decodeCSVRecords(line, br)
+		}
+		if err == io.EOF {
+			return nil, fmt.Errorf("missing CSV header")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func decodeCSVRecords(header string, r io.Reader) ([]METAR, error) {
+
+	cr := csv.NewReader(io.MultiReader(strings.NewReader(header), r))
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	hdr, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	cols := append([]string(nil), hdr...)
+
+	var out []METAR
+
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		m, err := decodeCSVRecord(cols, rec)
+		if err != nil || m.ICAOID == "" {
+			continue
+		}
+
+		out = append(out, m)
+	}
+}
+
+func decodeCSVRecord(cols []string, rec []string) (METAR, error) {
+
+	var (
+		m     METAR
+		cover string
+	)
+
+	for i, v := range rec {
+		if i >= len(cols) {
+			break
+		}
+		switch cols[i] {
+		case "sky_cover":
+			cover = v
+		case "cloud_base_ft_agl":
+			if err := addADDSSkyCondition(&m, cover, v); err != nil {
+				return METAR{}, err
+			}
+			cover = ""
+		default:
+			if err := setADDSField(&m, cols[i], v); err != nil {
+				return METAR{}, err
+			}
+		}
+	}
+
+	// A sky cover in the last column has no base column after it.
+	if err := addADDSSkyCondition(&m, cover, ""); err != nil {
+		return METAR{}, err
+	}
+
+	return m, nil
+}
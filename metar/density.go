@@ -0,0 +1,127 @@
+package metar
+
+import (
+	"math"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+const (
+	// unknownElevation is reported as the elevation of stations whose
+	// elevation is not known.
+	unknownElevation = 9999
+
+	// standardPressure is the ISA sea level pressure in hectopascals.
+	standardPressure = 1013.25
+
+	// DefaultDensityAltitudePeriod is how often an airport with high density
+	// altitude swells toward the warning color.
+	DefaultDensityAltitudePeriod = 3 * time.Second
+)
+
+var (
+	DefaultDensityAltitudeColor = ws2811.RGB{
+		Red:   255,
+		Green: 255,
+		Blue:  0,
+	}
+
+	// DefaultDensityAltitudeGradient runs from green at sea level to red at
+	// 10,000 ft.
+	DefaultDensityAltitudeGradient = Gradient{
+		{Value: 0, Color: ws2811.RGB{Red: 0, Green: 255, Blue: 0}},
+		{Value: 5000, Color: ws2811.RGB{Red: 255, Green: 255, Blue: 0}},
+		{Value: 8000, Color: ws2811.RGB{Red: 255, Green: 128, Blue: 0}},
+		{Value: 10000, Color: ws2811.RGB{Red: 255, Green: 0, Blue: 0}},
+	}
+)
+
+// ElevationFeet returns the field elevation in feet. It returns false when the
+// elevation is not known.
+func (m METAR) ElevationFeet() (float64, bool) {
+	if !m.HasElevation || m.Elevation >= unknownElevation {
+		return 0, false
+	}
+	return m.Elevation * feetPerMeter, true
+}
+
+// PressureAltitude returns the pressure altitude in feet from the field
+// elevation and altimeter setting. It returns false when either is not
+// known.
+func (m METAR) PressureAltitude() (float64, bool) {
+	elev, ok := m.ElevationFeet()
+	if !ok || m.Altimeter <= 0 {
+		return 0, false
+	}
+	return elev + 145366.45*(1-math.Pow(m.Altimeter/standardPressure, 0.190284)), true
+}
+
+// DensityAltitude returns the density altitude in feet, corrected for
+// humidity. It returns false when the field elevation, altimeter setting or
+// temperature is not known.
+func (m METAR) DensityAltitude() (float64, bool) {
+	elev, ok := m.ElevationFeet()
+	if !ok || m.Altimeter <= 0 || !m.HasTemperature {
+		return 0, false
+	}
+
+	// Station pressure from the altimeter setting.
+	stn := m.Altimeter * math.Pow((288-0.0065*elev/feetPerMeter)/288, 5.2561)
+
+	// Vapor pressure at the dewpoint, and the virtual temperature.
+	var vp float64
+	if m.HasDewpoint {
+		vp = 6.11 * math.Pow(10, 7.5*m.Dewpoint/(237.7+m.Dewpoint))
+	}
+	tv := (m.Temperature + 273.15) / (1 - vp/stn*(1-0.622))
+
+	rankine := tv * 9 / 5
+	inHg := stn / hectopascalsPerInHg
+
+	return 145366 * (1 - math.Pow(17.326*inHg/rankine, 0.235)), true
+}
+
+// DensityAltitudeOptions configure the warning on airports with high density
+// altitude. Zero thresholds are not checked.
+type DensityAltitudeOptions struct {
+	// Threshold is the density altitude in feet at or above which an airport
+	// is flagged.
+	Threshold float64
+	// Margin is how many feet above the field elevation the density
+	// altitude can be before an airport is flagged.
+	Margin float64
+	// Color is the warning color. Zero uses DefaultDensityAltitudeColor.
+	Color ws2811.RGB
+}
+
+// High reports whether the density altitude of m is at or above the
+// threshold, or more than the margin above the field elevation.
+func (o DensityAltitudeOptions) High(m METAR) bool {
+	da, ok := m.DensityAltitude()
+	if !ok {
+		return false
+	}
+	if o.Threshold > 0 && da >= o.Threshold {
+		return true
+	}
+	elev, _ := m.ElevationFeet()
+	return o.Margin > 0 && da-elev > o.Margin
+}
+
+func (o DensityAltitudeOptions) color() ws2811.RGB {
+	if o.Color != (ws2811.RGB{}) {
+		return o.Color
+	}
+	return DefaultDensityAltitudeColor
+}
+
+// effect swells base toward the warning color.
+func (o DensityAltitudeOptions) effect(base ws2811.Effect) ws2811.Effect {
+	return ws2811.Surge{
+		Base:     base,
+		Peak:     o.color(),
+		Period:   DefaultDensityAltitudePeriod,
+		Duration: DefaultDensityAltitudePeriod / 2,
+	}
+}
@@ -0,0 +1,197 @@
+package metar_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+// station returns a METAR reporting elevation, altimeter, temperature and
+// dewpoint.
+func station(elev, altim, temp, dewp float64) metar.METAR {
+	return metar.METAR{
+		Elevation:      elev,
+		HasElevation:   true,
+		Altimeter:      altim,
+		Temperature:    temp,
+		HasTemperature: true,
+		Dewpoint:       dewp,
+		HasDewpoint:    true,
+	}
+}
+
+func TestDensityAltitude(t *testing.T) {
+	type fixture struct {
+		name string
+		m    metar.METAR
+		pa   float64
+		da   float64
+		ok   bool
+	}
+
+	fixtures := []fixture{
+		{name: "standard day", m: station(0, 1013.25, 15, -40), pa: 0, da: 19, ok: true},
+		{name: "humid standard day", m: station(0, 1013.25, 15, 15), pa: 0, da: 234, ok: true},
+		{name: "hot mile high", m: station(1609, 1013.25, 35, 0), pa: 5279, da: 8770, ok: true},
+		{name: "cool mile high", m: station(1609, 1013.25, 5, 0), pa: 5279, da: 5442, ok: true},
+		{name: "dry mile high", m: metar.METAR{Elevation: 1609, HasElevation: true, Altimeter: 1013.25, Temperature: 35, HasTemperature: true}, pa: 5279, da: 8681, ok: true},
+		// KMYP 141602Z AUTO 24015KT 10SM CLR 02/M13 A3046
+		{name: "high pressure", m: station(3629, 1031.6, 2, -13), pa: 11409, da: 12632, ok: true},
+		{name: "unknown elevation", m: station(9999, 1014, 20, 5)},
+		{name: "no altimeter", m: station(1609, 0, 20, 5)},
+	}
+
+	for _, fix := range fixtures {
+		t.Run(fix.name, func(t *testing.T) {
+			pa, ok := fix.m.PressureAltitude()
+			if ok != fix.ok {
+				t.Fatalf("expected %v, got %v", fix.ok, ok)
+			}
+			if math.Round(pa) != fix.pa {
+				t.Fatalf("expected pressure altitude %v, got %v", fix.pa, pa)
+			}
+			da, ok := fix.m.DensityAltitude()
+			if ok != fix.ok {
+				t.Fatalf("expected %v, got %v", fix.ok, ok)
+			}
+			if math.Round(da) != fix.da {
+				t.Fatalf("expected density altitude %v, got %v", fix.da, da)
+			}
+		})
+	}
+}
+
+func TestDensityAltitudeUnknown(t *testing.T) {
+
+	raw, err := metar.ParseRawAt("KDEN 141553Z 18010KT 10SM CLR 30/M05 A3000", time.Date(2024, 4, 14, 18, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	noTemp := station(1609, 1013.25, 0, 0)
+	noTemp.HasTemperature = false
+
+	type fixture struct {
+		name string
+		m    metar.METAR
+	}
+
+	fixtures := []fixture{
+		{name: "raw", m: raw},
+		{name: "no temperature", m: noTemp},
+	}
+
+	for _, fix := range fixtures {
+		t.Run(fix.name, func(t *testing.T) {
+			if da, ok := fix.m.DensityAltitude(); ok {
+				t.Fatalf("expected unknown density altitude, got %v", da)
+			}
+			if (metar.DensityAltitudeOptions{Threshold: 1}).High(fix.m) {
+				t.Fatal("expected not high")
+			}
+		})
+	}
+}
+
+func TestDensityAltitudeOptions(t *testing.T) {
+	type fixture struct {
+		name string
+		opts metar.DensityAltitudeOptions
+		exp  bool
+	}
+
+	// 8770 ft density altitude at a 5279 ft field.
+	m := station(1609, 1013.25, 35, 0)
+
+	fixtures := []fixture{
+		{name: "below threshold", opts: metar.DensityAltitudeOptions{Threshold: 9000}},
+		{name: "above threshold", opts: metar.DensityAltitudeOptions{Threshold: 8000}, exp: true},
+		{name: "within margin", opts: metar.DensityAltitudeOptions{Margin: 4000}},
+		{name: "above margin", opts: metar.DensityAltitudeOptions{Margin: 3000}, exp: true},
+		{name: "either", opts: metar.DensityAltitudeOptions{Threshold: 9000, Margin: 3000}, exp: true},
+		{name: "disabled"},
+	}
+
+	for _, fix := range fixtures {
+		t.Run(fix.name, func(t *testing.T) {
+			if got := fix.opts.High(m); got != fix.exp {
+				t.Fatalf("expected %v, got %v", fix.exp, got)
+			}
+		})
+	}
+}
+
+func TestColorServerDensityAltitude(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"KMYP", "KCGS", "KQFX"},
+		LEDIndexByAirportID: map[string]int{
+			"KMYP": 0,
+			"KCGS": 1,
+			"KQFX": 2,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: 100000 * time.Hour,
+		DensityAltitude: &metar.DensityAltitudeOptions{
+			Threshold: 10000,
+		},
+	}
+
+	sts, err := srv.GetMETARs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exp := map[int]metar.Status{
+		// 12,600 ft density altitude.
+		0: {FlightCategory: metar.FlightCategoryVFR, HighDensityAltitude: true},
+		1: {FlightCategory: metar.FlightCategoryVFR},
+		// Unknown elevation.
+		2: {FlightCategory: metar.FlightCategoryVFR},
+	}
+
+	for idx, st := range exp {
+		if sts[idx] != st {
+			t.Fatalf("expected %v at %d, got %v", st, idx, sts[idx])
+		}
+	}
+
+	state := srv.StatusToState(sts)
+
+	vfr := metar.DefaultColors[metar.FlightCategoryVFR]
+	expState := ws2811.Surge{
+		Base:     ws2811.Static(vfr),
+		Peak:     metar.DefaultDensityAltitudeColor,
+		Period:   metar.DefaultDensityAltitudePeriod,
+		Duration: metar.DefaultDensityAltitudePeriod / 2,
+	}
+	if state[0] != expState {
+		t.Fatalf("expected %v at 0, got %v", expState, state[0])
+	}
+	if exp := ws2811.Static(vfr); state[1] != exp {
+		t.Fatalf("expected %v at 1, got %v", exp, state[1])
+	}
+
+	srv.Mode = metar.ModeDensityAltitude
+
+	sts, err = srv.GetMETARs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	colors := srv.StatusToRGB(sts)
+	if exp := metar.DefaultDensityAltitudeGradient.Color(sts[0].Value); colors[0] != exp || sts[0].Value < 12000 {
+		t.Fatalf("expected %v for %v ft, got %v", exp, sts[0].Value, colors[0])
+	}
+	if exp := metar.DefaultColors[metar.FlightCategoryUnknown]; colors[2] != exp {
+		t.Fatalf("expected %v at 2, got %v", exp, colors[2])
+	}
+}
@@ -0,0 +1,127 @@
+package metar
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DirectoryProvider gets METARs from files in a local directory. Files ending
+// in .xml or .csv are decoded as ADDS XML or CSV, and other files as raw
+// reports, one per line. A line such as "2024/04/14 15:52" sets the date of
+// the reports after it.
+type DirectoryProvider struct {
+	Dir    string
+	Logger *slog.Logger
+}
+
+func (p DirectoryProvider) log(f func(l *slog.Logger)) {
+	if p.Logger == nil {
+		return
+	}
+	f(p.Logger.With("pkg", "metar", "func", "DirectoryProvider.GetMETARs"))
+}
+
+func (p DirectoryProvider) GetMETARs(ctx context.Context, airportIDs ...string) (map[string]METAR, error) {
+
+	if len(airportIDs) == 0 {
+		return nil, fmt.Errorf("no airport identifiers specified")
+	}
+
+	entries, err := os.ReadDir(p.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read METAR directory: %w", err)
+	}
+
+	var all []METAR
+
+	for _, ent := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !ent.Type().IsRegular() || strings.HasPrefix(ent.Name(), ".") {
+			continue
+		}
+		metars, err := readMETARFile(filepath.Join(p.Dir, ent.Name()))
+		if err != nil {
+			p.log(func(l *slog.Logger) {
+				l.Warn("skipping METAR file", "file", ent.Name(), "error", err)
+			})
+			continue
+		}
+		all = append(all, metars...)
+	}
+
+	return filterMETARs(all, airportIDs), nil
+}
+
+func readMETARFile(pth string) ([]METAR, error) {
+	switch strings.ToLower(filepath.Ext(pth)) {
+	case ".xml":
+		return readDecodedFile(pth, DecodeXML)
+	case ".csv":
+		return readDecodedFile(pth, DecodeCSV)
+	}
+	return readRawFile(pth)
+}
+
+func readDecodedFile(pth string, decode func(r io.Reader) ([]METAR, error)) ([]METAR, error) {
+
+	f, err := os.Open(pth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open METAR file: %w", err)
+	}
+	defer f.Close()
+
+	metars, err := decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode METAR file %s: %w", filepath.Base(pth), err)
+	}
+
+	return metars, nil
+}
+
+func readRawFile(pth string) ([]METAR, error) {
+
+	f, err := os.Open(pth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open METAR file: %w", err)
+	}
+	defer f.Close()
+
+	ref := time.Now().UTC()
+	if fi, err := f.Stat(); err == nil {
+		ref = fi.ModTime().UTC()
+	}
+
+	var out []METAR
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if t, err := time.Parse("2006/01/02 15:04", line); err == nil {
+			ref = t
+			continue
+		}
+		m, err := ParseRawAt(line, ref)
+		if err != nil {
+			continue
+		}
+		out = append(out, m)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read METAR file: %w", err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,44 @@
+package metar
+
+import (
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+// StatusToState returns the effect of each LED for statuses. Stale and
+// missing airports are not animated.
+func (srv *ColorServer) StatusToState(statuses map[int]Status) ws2811.State {
+
+	colors := srv.StatusToRGB(statuses)
+
+	out := make(ws2811.State, len(colors))
+
+	for idx, c := range colors {
+		var eff ws2811.Effect = ws2811.Static(c)
+
+		st := statuses[idx]
+		if st.Stale || st.Missing {
+			out[idx] = eff
+			continue
+		}
+
+		if st.HighWind && srv.Wind != nil {
+			eff = srv.Wind.effect(c, st.GustFactor)
+		}
+
+		if srv.Mode == ModeWind {
+			eff = srv.windModeEffect(eff, c, st)
+		}
+
+		if st.HighDensityAltitude && srv.DensityAltitude != nil {
+			eff = srv.DensityAltitude.effect(eff)
+		}
+
+		if st.Lightning && srv.Lightning != nil {
+			eff = srv.Lightning.effect(eff)
+		}
+
+		out[idx] = eff
+	}
+
+	return out
+}
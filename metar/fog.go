@@ -0,0 +1,124 @@
+package metar
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+const (
+	DefaultFogHours     = 2
+	DefaultFogLookahead = time.Hour
+)
+
+// DefaultFogGradient is red at no spread and dim green above 3°C.
+var DefaultFogGradient = Gradient{
+	{Value: 0, Color: ws2811.RGB{Red: 255, Green: 0, Blue: 0}},
+	{Value: 1, Color: ws2811.RGB{Red: 255, Green: 128, Blue: 0}},
+	{Value: 2, Color: ws2811.RGB{Red: 255, Green: 255, Blue: 0}},
+	{Value: 3, Color: ws2811.RGB{Red: 255, Green: 255, Blue: 0}},
+	{Value: 3, Color: ws2811.RGB{Red: 0, Green: 32, Blue: 0}},
+}
+
+// FogOptions configure ModeFog.
+type FogOptions struct {
+	// Hours of observations to search for the previous one.
+	Hours int
+	// Lookahead is how far ahead a shrinking spread is projected.
+	Lookahead time.Duration
+}
+
+func (opts FogOptions) hours() int {
+	if opts.Hours > 0 {
+		return opts.Hours
+	}
+	return DefaultFogHours
+}
+
+func (opts FogOptions) lookahead() time.Duration {
+	if opts.Lookahead > 0 {
+		return opts.Lookahead
+	}
+	return DefaultFogLookahead
+}
+
+// Spread returns the temperature/dewpoint spread in degrees Celsius. It
+// returns false when the temperature or dewpoint is not reported.
+func (m METAR) Spread() (float64, bool) {
+	if !m.HasTemperature || !m.HasDewpoint {
+		return 0, false
+	}
+	return math.Max(m.Temperature-m.Dewpoint, 0), true
+}
+
+// ProjectedSpread returns the spread of m projected ahead by lookahead at the
+// rate it shrank since prev. It returns false when m has no spread.
+func (m METAR) ProjectedSpread(prev METAR, lookahead time.Duration) (float64, bool) {
+	spread, ok := m.Spread()
+	if !ok {
+		return 0, false
+	}
+
+	last, ok := prev.Spread()
+	elapsed := time.Time(m.ObservationTime).Sub(time.Time(prev.ObservationTime))
+	if !ok || time.Time(prev.ObservationTime).IsZero() || elapsed <= 0 {
+		return spread, true
+	}
+
+	shrink := last - spread
+	if shrink <= 0 {
+		return spread, true
+	}
+
+	return math.Max(spread-shrink*lookahead.Hours()/elapsed.Hours(), 0), true
+}
+
+// historyProvider returns the provider, or first link of a Chain, with history.
+func (srv *ColorServer) historyProvider() (HistoryProvider, bool) {
+	switch p := srv.provider().(type) {
+	case HistoryProvider:
+		return p, true
+	case *Chain:
+		for _, lnk := range p.Providers {
+			if hp, ok := lnk.Provider.(HistoryProvider); ok {
+				return hp, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// fetchPrevious gets the observation before the current one of each airport.
+func (srv *ColorServer) fetchPrevious(ctx context.Context, metars map[string]METAR) map[string]METAR {
+
+	hp, ok := srv.historyProvider()
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, srv.timeout())
+	defer cancel()
+
+	history, err := hp.GetMETARHistory(ctx, srv.Fog.hours(), srv.AirportIDs...)
+	if err != nil {
+		srv.log(func(l *slog.Logger) {
+			l.Warn("failed to get previous METARs, using current spread", "error", err)
+		})
+	}
+
+	out := make(map[string]METAR, len(history))
+
+	for id, m := range metars {
+		for _, prev := range history[id] {
+			if m.ObservationTime.After(prev.ObservationTime) {
+				out[id] = prev
+				break
+			}
+		}
+	}
+
+	return out
+}
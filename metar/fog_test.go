@@ -0,0 +1,305 @@
+package metar_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+)
+
+func TestProjectedSpread(t *testing.T) {
+
+	type fixture struct {
+		name string
+		m    metar.METAR
+		prev metar.METAR
+		exp  float64
+		ok   bool
+	}
+
+	at := time.Date(2024, 4, 14, 6, 0, 0, 0, time.UTC)
+
+	ob := func(ago time.Duration, temp, dewp float64) metar.METAR {
+		return metar.METAR{
+			ObservationTime: metar.Time(at.Add(-ago)),
+			Temperature:     temp,
+			HasTemperature:  true,
+			Dewpoint:        dewp,
+			HasDewpoint:     true,
+		}
+	}
+
+	m := ob(0, 10, 8)
+
+	noDewpoint := ob(time.Hour, 14, 0)
+	noDewpoint.HasDewpoint = false
+
+	fixtures := []fixture{
+		{name: "no previous", m: m, exp: 2, ok: true},
+		{name: "shrinking", m: m, prev: ob(2*time.Hour, 11, 8), exp: 1.5, ok: true},
+		{name: "shrinking fast", m: m, prev: ob(time.Hour, 13, 8), exp: 0, ok: true},
+		{name: "growing", m: m, prev: ob(time.Hour, 9, 8), exp: 2, ok: true},
+		{name: "not earlier", m: m, prev: ob(0, 14, 8), exp: 2, ok: true},
+		{name: "previous without dewpoint", m: m, prev: noDewpoint, exp: 2, ok: true},
+		{name: "without dewpoint", m: noDewpoint},
+	}
+
+	for _, fix := range fixtures {
+		t.Run(fix.name, func(t *testing.T) {
+			got, ok := fix.m.ProjectedSpread(fix.prev, time.Hour)
+			if ok != fix.ok {
+				t.Fatalf("expected %v, got %v", fix.ok, ok)
+			}
+			if got != fix.exp {
+				t.Fatalf("expected %v, got %v", fix.exp, got)
+			}
+		})
+	}
+}
+
+// newFogServer serves the latest observation of each airport, or every
+// observation when asked for hours of history.
+func newFogServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	at := time.Date(2024, 4, 14, 6, 0, 0, 0, time.UTC)
+
+	ob := func(id string, ago time.Duration, temp, dewp float64) map[string]any {
+		return map[string]any{
+			"icaoId":  id,
+			"obsTime": at.Add(-ago).Unix(),
+			"temp":    temp,
+			"dewp":    dewp,
+			"visib":   "10+",
+			"clouds":  []any{},
+		}
+	}
+
+	// Newest first, as the API returns them.
+	history := []map[string]any{
+		ob("KAAA", 0, 10, 8),
+		ob("KAAA", time.Hour, 13, 8),
+		ob("KAAA", 2*time.Hour, 10, 8),
+		ob("KBBB", 0, 10, 5),
+		ob("KBBB", time.Hour, 10, 7),
+		ob("KCCC", 0, 10, 9),
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		out := history
+		if hours := r.URL.Query().Get("hours"); hours != "" {
+			requests.Add(1)
+			if hours != "2" {
+				t.Errorf("unexpected hours: %s", hours)
+			}
+		} else {
+			out = nil
+			for _, m := range history {
+				if m["obsTime"] == at.Unix() {
+					out = append(out, m)
+				}
+			}
+		}
+		ids := r.URL.Query().Get("ids")
+		var body []map[string]any
+		for _, m := range out {
+			if strings.Contains(ids, m["icaoId"].(string)) {
+				body = append(body, m)
+			}
+		}
+		w.Header().Set("content-type", "application/json")
+		json.NewEncoder(w).Encode(body)
+	}))
+}
+
+func TestGetMETARHistory(t *testing.T) {
+
+	var requests atomic.Int32
+
+	api := newFogServer(t, &requests)
+	defer api.Close()
+
+	c := metar.Client{BaseURL: api.URL}
+
+	history, err := c.GetMETARHistory(context.Background(), 2, "KAAA", "KBBB")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("expected 1 history request, got %d", n)
+	}
+
+	if n := len(history["KAAA"]); n != 3 {
+		t.Fatalf("expected 3 observations, got %d", n)
+	}
+	if n := len(history["KBBB"]); n != 2 {
+		t.Fatalf("expected 2 observations, got %d", n)
+	}
+	for i := 1; i < len(history["KAAA"]); i++ {
+		if history["KAAA"][i].ObservationTime.After(history["KAAA"][i-1].ObservationTime) {
+			t.Fatalf("expected newest first, got %v", history["KAAA"])
+		}
+	}
+
+	if _, err := c.GetMETARHistory(context.Background(), 0, "KAAA"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestColorServerFog(t *testing.T) {
+
+	var requests atomic.Int32
+
+	api := newFogServer(t, &requests)
+	defer api.Close()
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"KAAA", "KBBB", "KCCC"},
+		LEDIndexByAirportID: map[string]int{
+			"KAAA": 0,
+			"KBBB": 1,
+			"KCCC": 2,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: 100000 * time.Hour,
+	}
+
+	if _, err := srv.GetMETARs(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Fatalf("expected no history requests outside fog mode, got %d", n)
+	}
+
+	srv.Mode = metar.ModeFog
+
+	sts, err := srv.GetMETARs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("expected 1 history request, got %d", n)
+	}
+
+	exp := map[int]float64{
+		// Shrunk from 5°C to 2°C in the last hour.
+		0: 0,
+		// Growing.
+		1: 5,
+		// No previous observation.
+		2: 1,
+	}
+
+	for idx, v := range exp {
+		if !sts[idx].HasValue || sts[idx].Value != v {
+			t.Fatalf("expected %v at %d, got %v", v, idx, sts[idx])
+		}
+	}
+
+	colors := srv.StatusToRGB(sts)
+	for idx, v := range exp {
+		if c := metar.DefaultFogGradient.Color(v); colors[idx] != c {
+			t.Fatalf("expected %v at %d, got %v", c, idx, colors[idx])
+		}
+	}
+	if colors[0] == colors[1] {
+		t.Fatalf("expected a shrinking spread to stand out, got %v", colors)
+	}
+}
+
+func TestColorServerFogWithoutHistory(t *testing.T) {
+
+	var requests atomic.Int32
+
+	api := newFogServer(t, &requests)
+	defer api.Close()
+
+	at := time.Date(2024, 4, 14, 6, 0, 0, 0, time.UTC)
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"KAAA"},
+		LEDIndexByAirportID: map[string]int{
+			"KAAA": 0,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		Provider: metar.ProviderFunc(func(ctx context.Context, airportIDs ...string) (map[string]metar.METAR, error) {
+			return map[string]metar.METAR{
+				"KAAA": {
+					ICAOID:          "KAAA",
+					ObservationTime: metar.Time(at),
+					Temperature:     10,
+					HasTemperature:  true,
+					Dewpoint:        8,
+					HasDewpoint:     true,
+				},
+			}, nil
+		}),
+		StaleAfter: 100000 * time.Hour,
+		Mode:       metar.ModeFog,
+	}
+
+	sts, err := srv.GetMETARs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Fatalf("expected no history requests, got %d", n)
+	}
+
+	// The current spread, as there is no previous observation.
+	if !sts[0].HasValue || sts[0].Value != 2 {
+		t.Fatalf("expected 2 at 0, got %v", sts[0])
+	}
+}
+
+func TestColorServerFogUnreported(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"SBTU", "SBTE", "PAOU"},
+		LEDIndexByAirportID: map[string]int{
+			"SBTU": 0,
+			"SBTE": 1,
+			"PAOU": 2,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: 100000 * time.Hour,
+		Mode:       metar.ModeFog,
+	}
+
+	sts, err := srv.GetMETARs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	colors := srv.StatusToRGB(sts)
+
+	// No temperature or dewpoint reported.
+	for _, idx := range []int{0, 1} {
+		if sts[idx].HasValue {
+			t.Fatalf("expected no value at %d, got %v", idx, sts[idx].Value)
+		}
+		if exp := metar.DefaultColors[metar.FlightCategoryUnknown]; colors[idx] != exp {
+			t.Fatalf("expected %v at %d, got %v", exp, idx, colors[idx])
+		}
+	}
+
+	// 01/M02
+	if !sts[2].HasValue || sts[2].Value != 3 {
+		t.Fatalf("expected 3 at 2, got %v", sts[2])
+	}
+}
@@ -0,0 +1,143 @@
+package metar
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+const (
+	DefaultForecastHours   = 12
+	DefaultForecastStep    = 2 * time.Second
+	DefaultForecastCurrent = 5 * time.Second
+
+	// forecastSeparator is how long the strip is blanked before each loop.
+	forecastSeparator = 500 * time.Millisecond
+)
+
+// ForecastOptions configure ModeForecast.
+type ForecastOptions struct {
+	// Hours is how many hours ahead of now to display.
+	Hours int
+	// Step is how long each forecast hour is displayed.
+	Step time.Duration
+	// Current is how long the current conditions are displayed at the start
+	// of each loop.
+	Current time.Duration
+}
+
+func (opts ForecastOptions) hours() int {
+	if opts.Hours > 0 {
+		return opts.Hours
+	}
+	return DefaultForecastHours
+}
+
+func (opts ForecastOptions) step() time.Duration {
+	if opts.Step > 0 {
+		return opts.Step
+	}
+	return DefaultForecastStep
+}
+
+func (opts ForecastOptions) current() time.Duration {
+	if opts.Current > 0 {
+		return opts.Current
+	}
+	return DefaultForecastCurrent
+}
+
+// GetForecast returns the flight categories of each LED for the current
+// METARs followed by the TAF forecast for each hour after now. Airports
+// without a TAF valid at a given hour keep their current category.
+func (srv *ColorServer) GetForecast(ctx context.Context, now time.Time) ([]map[int]FlightCategory, error) {
+
+	current, hours, err := srv.forecast(ctx, now)
+	if current == nil {
+		return nil, err
+	}
+
+	fcs := make(map[int]FlightCategory, len(current))
+	for idx, st := range current {
+		if !st.Missing {
+			fcs[idx] = st.FlightCategory
+		}
+	}
+
+	return append([]map[int]FlightCategory{fcs}, hours...), err
+}
+
+func (srv *ColorServer) forecast(ctx context.Context, now time.Time) (map[int]Status, []map[int]FlightCategory, error) {
+
+	to := srv.timeout()
+
+	ctx, cancel := context.WithTimeout(ctx, to)
+	defer cancel()
+
+	srv.log(func(l *slog.Logger) {
+		l.Info("getting METARs and TAFs", "timeout", to)
+	})
+
+	metars, err := srv.fetchMETARs(ctx)
+	if err != nil && len(metars) == 0 {
+		return nil, nil, err
+	}
+
+	tafs, tafErr := srv.Client.GetTAFs(ctx, srv.AirportIDs...)
+	if tafErr != nil {
+		srv.log(func(l *slog.Logger) {
+			l.Warn("failed to get TAFs, using METARs", "error", tafErr)
+		})
+	}
+
+	hours := srv.Forecast.hours()
+
+	out := make([]map[int]FlightCategory, 0, hours)
+
+	for h := 1; h <= hours; h++ {
+		at := now.Add(time.Duration(h) * time.Hour)
+		fcs := make(map[int]FlightCategory, len(srv.AirportIDs))
+
+		for _, id := range srv.AirportIDs {
+			idx, ok := srv.LEDIndexByAirportID[id]
+			if !ok {
+				continue
+			}
+			if fc, ok := srv.Rules.forecastFlightCategory(tafs, id, at); ok {
+				fcs[idx] = fc
+				continue
+			}
+			if wx, ok := metars[id]; ok {
+				fcs[idx] = srv.Rules.FlightCategory(wx)
+			}
+		}
+
+		out = append(out, fcs)
+	}
+
+	return srv.statuses(metars, now), out, err
+}
+
+// forecastFrames builds one loop of the forecast display.
+func (srv *ColorServer) forecastFrames(current ws2811.State, missing map[int]ws2811.RGB, hours []map[int]FlightCategory) []frame {
+
+	frames := make([]frame, 0, len(hours)+2)
+	frames = append(frames,
+		frame{state: ws2811.State{}, dur: forecastSeparator},
+		frame{state: current, dur: srv.Forecast.current()},
+	)
+
+	for _, fcs := range hours {
+		colors := srv.FlightCategoryToRGB(fcs)
+		for idx, c := range missing {
+			if _, ok := colors[idx]; !ok {
+				colors[idx] = c
+			}
+		}
+		frames = append(frames, frame{state: ws2811.StaticState(colors), dur: srv.Forecast.step()})
+	}
+
+	return frames
+}
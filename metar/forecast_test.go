@@ -0,0 +1,125 @@
+package metar_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+func TestColorServerGetForecast(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"KBOS", "CYYL"},
+		LEDIndexByAirportID: map[string]int{
+			"KBOS": 0,
+			"CYYL": 1,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		Forecast: metar.ForecastOptions{
+			Hours: 3,
+		},
+	}
+
+	now := time.Date(2024, 4, 15, 4, 30, 0, 0, time.UTC)
+
+	steps, err := srv.GetForecast(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exp := []map[int]metar.FlightCategory{
+		{1: metar.FlightCategoryIFR},
+		{0: metar.FlightCategoryVFR, 1: metar.FlightCategoryIFR},
+		{0: metar.FlightCategoryIFR, 1: metar.FlightCategoryIFR},
+		{0: metar.FlightCategoryIFR, 1: metar.FlightCategoryIFR},
+	}
+
+	if len(steps) != len(exp) {
+		t.Fatalf("expected %d steps, got %d", len(exp), len(steps))
+	}
+
+	for i := range exp {
+		if len(steps[i]) != len(exp[i]) {
+			t.Fatalf("step %d: expected %v, got %v", i, exp[i], steps[i])
+		}
+		for idx, cat := range exp[i] {
+			if steps[i][idx] != cat {
+				t.Fatalf("step %d: expected %v, got %v", i, exp[i], steps[i])
+			}
+		}
+	}
+}
+
+func TestColorServerForecastStatus(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"KCGS", "KAVP", "KXXX"},
+		LEDIndexByAirportID: map[string]int{
+			"KCGS": 0,
+			"KAVP": 1,
+			"KXXX": 2,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: 100000 * time.Hour,
+		Lightning:  &metar.LightningOptions{},
+		Mode:       metar.ModeForecast,
+		Forecast: metar.ForecastOptions{
+			Hours:   1,
+			Current: 10 * time.Millisecond,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leds := make(chan ws2811.State)
+
+	go srv.Serve(ctx, everySchedule(time.Hour), leds)
+
+	next := func() ws2811.State {
+		select {
+		case state := <-leds:
+			return state
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for state")
+		}
+		return nil
+	}
+
+	if state := next(); len(state) != 0 {
+		t.Fatalf("expected a blank separator, got %v", state)
+	}
+
+	current := next()
+
+	if st, ok := srv.LEDStatus(2); !ok || !st.Missing {
+		t.Fatalf("expected missing at 2, got %v (%v)", st, ok)
+	}
+
+	now := time.Now()
+
+	if c := current[2].At(now); c != metar.DefaultMissingColor {
+		t.Fatalf("expected %v at 2, got %v", metar.DefaultMissingColor, c)
+	}
+
+	if _, ok := current[1].(ws2811.Static); ok {
+		t.Fatalf("expected lightning to flash at 1, got %v", current[1])
+	}
+
+	hour := next()
+
+	if c := hour[2].At(now); c != metar.DefaultMissingColor {
+		t.Fatalf("expected %v at 2, got %v", metar.DefaultMissingColor, c)
+	}
+}
@@ -1,23 +1,55 @@
 package metar
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
 	DefaultBaseURL = "https://aviationweather.gov/api/data"
 )
 
+// Format is a response format of the AWC API.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatXML  Format = "xml"
+	FormatCSV  Format = "csv"
+)
+
 type Client struct {
 	HTTPClient *http.Client
 	BaseURL    string
+	Retry      RetryPolicy
+	Breaker    *CircuitBreaker
+	// BatchSize is the most airports to request at once. Zero requests every
+	// airport in one request.
+	BatchSize int
+	// Concurrency is the most batches to request at once.
+	Concurrency int
+	// Format is the response format requested for METARs. TAFs are always
+	// requested as JSON.
+	Format Format
+}
+
+func (c Client) format() Format {
+	if c.Format == "" {
+		return FormatJSON
+	}
+	return c.Format
 }
 
 func (c Client) Route(pth string) (*url.URL, error) {
@@ -43,23 +75,256 @@ func (c Client) Do(r *http.Request) (*http.Response, error) {
 	return hc.Do(r)
 }
 
+// GetMETARs gets the latest METAR for each airport. When some batches of
+// airports fail, the METARs from the rest are returned with a *BatchError.
 func (c Client) GetMETARs(ctx context.Context, airportIDs ...string) (map[string]METAR, error) {
 
 	if len(airportIDs) == 0 {
 		return nil, fmt.Errorf("no airport identifiers specified")
 	}
 
-	u, err := c.Route("/metar")
+	var mu sync.Mutex
+
+	out := make(map[string]METAR)
+
+	err := c.forEachBatch(ctx, airportIDs, func(ctx context.Context, batch []string) error {
+
+		bdy, err := c.getMETARs(ctx, batch, nil)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, m := range bdy {
+			latest(out, m)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return out, fmt.Errorf("failed retrieving METAR(s): %w", err)
+	}
+
+	return out, nil
+}
+
+// GetMETARHistory gets the METARs of each airport observed in the last hours,
+// newest first. When some batches of airports fail, the METARs from the rest
+// are returned with a *BatchError.
+func (c Client) GetMETARHistory(ctx context.Context, hours int, airportIDs ...string) (map[string][]METAR, error) {
+
+	if len(airportIDs) == 0 {
+		return nil, fmt.Errorf("no airport identifiers specified")
+	}
+
+	if hours <= 0 {
+		return nil, fmt.Errorf("invalid hours: %d", hours)
+	}
+
+	params := url.Values{}
+	params.Set("hours", strconv.Itoa(hours))
+
+	var mu sync.Mutex
+
+	out := make(map[string][]METAR)
+
+	err := c.forEachBatch(ctx, airportIDs, func(ctx context.Context, batch []string) error {
+
+		bdy, err := c.getMETARs(ctx, batch, params)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, m := range bdy {
+			out[m.ICAOID] = append(out[m.ICAOID], m)
+		}
+
+		return nil
+	})
+
+	for _, metars := range out {
+		sort.SliceStable(metars, func(i, j int) bool {
+			return metars[i].ObservationTime.After(metars[j].ObservationTime)
+		})
+	}
+
+	if err != nil {
+		return out, fmt.Errorf("failed retrieving METAR history: %w", err)
+	}
+
+	return out, nil
+}
+
+// GetTAFs gets the latest TAF for each airport. When some batches of
+// airports fail, the TAFs from the rest are returned with a *BatchError.
+func (c Client) GetTAFs(ctx context.Context, airportIDs ...string) (map[string]TAF, error) {
+
+	if len(airportIDs) == 0 {
+		return nil, fmt.Errorf("no airport identifiers specified")
+	}
+
+	var mu sync.Mutex
+
+	out := make(map[string]TAF)
+
+	err := c.forEachBatch(ctx, airportIDs, func(ctx context.Context, batch []string) error {
+
+		var bdy []TAF
+
+		if err := c.getJSON(ctx, "/taf", batch, &bdy); err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, t := range bdy {
+			if prev, ok := out[t.ICAOID]; ok && !time.Time(t.IssueTime).After(time.Time(prev.IssueTime)) {
+				continue
+			}
+			out[t.ICAOID] = t
+		}
+
+		return nil
+	})
+	if err != nil {
+		return out, fmt.Errorf("failed retrieving TAF(s): %w", err)
+	}
+
+	return out, nil
+}
+
+func (c Client) getMETARs(ctx context.Context, airportIDs []string, params url.Values) ([]METAR, error) {
+
+	f := c.format()
+
+	bts, err := c.query(ctx, "/metar", airportIDs, f, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var metars []METAR
+
+	switch f {
+	case FormatJSON:
+		if err := unmarshalJSON(bts, &metars); err != nil {
+			return nil, err
+		}
+		return metars, nil
+	case FormatXML:
+		metars, err = DecodeXML(bytes.NewReader(bts))
+	case FormatCSV:
+		// No results are an empty body rather than a bare header.
+		if len(bytes.TrimSpace(bts)) == 0 {
+			return nil, nil
+		}
+		metars, err = DecodeCSV(bytes.NewReader(bts))
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return metars, nil
+}
+
+func (c Client) getJSON(ctx context.Context, pth string, airportIDs []string, v any) error {
+
+	bts, err := c.query(ctx, pth, airportIDs, FormatJSON, nil)
+	if err != nil {
+		return err
+	}
+
+	return unmarshalJSON(bts, v)
+}
+
+func unmarshalJSON(bts []byte, v any) error {
+	if err := json.Unmarshal(bts, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+func (c Client) query(ctx context.Context, pth string, airportIDs []string, f Format, params url.Values) ([]byte, error) {
+
+	u, err := c.Route(pth)
 	if err != nil {
 		return nil, err
 	}
 
 	q := u.Query()
+	for k, vs := range params {
+		q[k] = vs
+	}
 	q.Set("ids", strings.Join(airportIDs, ","))
-	q.Set("format", "json")
+	q.Set("format", string(f))
 	u.RawQuery = q.Encode()
 
-	r, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	return c.fetch(ctx, u.String())
+}
+
+// fetch gets the body of a URL, retrying temporary failures.
+func (c Client) fetch(ctx context.Context, u string) ([]byte, error) {
+
+	attempts := c.Retry.attempts()
+
+	for n := 0; ; n++ {
+
+		bts, err := c.fetchOnce(ctx, u)
+		if err == nil {
+			return bts, nil
+		}
+
+		if n+1 >= attempts || !isRetryable(ctx, err) {
+			return nil, err
+		}
+
+		delay := c.Retry.backoff(n)
+
+		var se *StatusError
+		if errors.As(err, &se) && se.RetryAfter > 0 {
+			delay = se.RetryAfter
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c Client) fetchOnce(ctx context.Context, u string) ([]byte, error) {
+
+	if err := c.Breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	bts, err := c.get(ctx, u)
+	if err != nil {
+		// A response that will not change on retry, such as a 404, still
+		// shows the server is up.
+		var se *StatusError
+		if errors.As(err, &se) && !se.Temporary() {
+			c.Breaker.Success()
+		} else {
+			c.Breaker.Failure()
+		}
+		return nil, err
+	}
+
+	c.Breaker.Success()
+
+	return bts, nil
+}
+
+func (c Client) get(ctx context.Context, u string) ([]byte, error) {
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -68,27 +333,19 @@ func (c Client) GetMETARs(ctx context.Context, airportIDs ...string) (map[string
 
 	resp, err := c.Do(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed retrieving METAR(s): %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var bdy []METAR
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		io.Copy(io.Discard, resp.Body)
+		return nil, newStatusError(resp)
+	}
 
 	bts, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if err := json.Unmarshal(bts, &bdy); err != nil {
-		fmt.Println(string(bts))
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	out := make(map[string]METAR)
-
-	for _, m := range bdy {
-		out[m.ICAOID] = m
-	}
-
-	return out, nil
+	return bts, nil
 }
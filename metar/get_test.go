@@ -2,6 +2,13 @@ package metar_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -42,3 +49,313 @@ func TestGetMETARs(t *testing.T) {
 		t.Logf("%s: %+v", k, v.FlightCategory().Name())
 	}
 }
+
+func TestGetMETARsRetries(t *testing.T) {
+
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "<html>unavailable</html>", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[{"icaoId":"KFIT","visib":"10+","clouds":[{"cover":"CLR"}]}]`))
+	}))
+	defer srv.Close()
+
+	c := metar.Client{
+		BaseURL: srv.URL,
+		Retry: metar.RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+		},
+	}
+
+	out, err := c.GetMETARs(context.Background(), "KFIT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cat := out["KFIT"].FlightCategory(); cat != metar.FlightCategoryVFR {
+		t.Fatalf("expected %v, got %v", metar.FlightCategoryVFR, cat)
+	}
+
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("expected 3 calls, got %d", n)
+	}
+}
+
+func TestGetMETARsStatusError(t *testing.T) {
+	type fixture struct {
+		name  string
+		code  int
+		calls int32
+	}
+
+	fixtures := []fixture{
+		{
+			name:  "not retried",
+			code:  http.StatusBadRequest,
+			calls: 1,
+		},
+		{
+			name:  "retried",
+			code:  http.StatusBadGateway,
+			calls: 2,
+		},
+	}
+
+	for _, f := range fixtures {
+		t.Run(f.name, func(t *testing.T) {
+			var calls atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				http.Error(w, "<html>error</html>", f.code)
+			}))
+			defer srv.Close()
+
+			c := metar.Client{
+				BaseURL: srv.URL,
+				Retry: metar.RetryPolicy{
+					MaxAttempts:    2,
+					InitialBackoff: time.Millisecond,
+				},
+			}
+
+			_, err := c.GetMETARs(context.Background(), "KFIT")
+
+			var se *metar.StatusError
+			if !errors.As(err, &se) {
+				t.Fatalf("expected status error, got %v", err)
+			}
+			if se.StatusCode != f.code {
+				t.Fatalf("expected %d, got %d", f.code, se.StatusCode)
+			}
+			if n := calls.Load(); n != f.calls {
+				t.Fatalf("expected %d calls, got %d", f.calls, n)
+			}
+		})
+	}
+}
+
+func TestGetMETARsHonorsRetryAfter(t *testing.T) {
+
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	c := metar.Client{
+		BaseURL: srv.URL,
+		Retry: metar.RetryPolicy{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		},
+	}
+
+	start := time.Now()
+
+	if _, err := c.GetMETARs(context.Background(), "KFIT"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d := time.Since(start); d < time.Second {
+		t.Fatalf("expected to wait for Retry-After, waited %v", d)
+	}
+}
+
+func TestGetMETARsCircuitBreaker(t *testing.T) {
+
+	var calls atomic.Int32
+	var healthy atomic.Bool
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	c := metar.Client{
+		BaseURL: srv.URL,
+		Retry: metar.RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: time.Millisecond,
+		},
+		Breaker: &metar.CircuitBreaker{
+			Threshold: 2,
+			Cooldown:  50 * time.Millisecond,
+		},
+	}
+
+	if _, err := c.GetMETARs(context.Background(), "KFIT"); !errors.Is(err, metar.ErrCircuitOpen) {
+		t.Fatalf("expected circuit open, got %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected 2 calls, got %d", n)
+	}
+
+	if _, err := c.GetMETARs(context.Background(), "KFIT"); !errors.Is(err, metar.ErrCircuitOpen) {
+		t.Fatalf("expected circuit open, got %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected no calls while open, got %d", n)
+	}
+
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+
+	if _, err := c.GetMETARs(context.Background(), "KFIT"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Breaker.IsOpen() {
+		t.Fatal("expected breaker closed")
+	}
+}
+
+func TestGetMETARsBatches(t *testing.T) {
+
+	var inFlight, maxInFlight, calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		ids := strings.Split(r.URL.Query().Get("ids"), ",")
+		if len(ids) > 2 {
+			t.Errorf("expected at most 2 ids, got %v", ids)
+		}
+
+		out := []map[string]string{}
+		for _, id := range ids {
+			if id == "FAIL" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			out = append(out, map[string]string{"icaoId": id})
+		}
+		json.NewEncoder(w).Encode(out)
+	}))
+	defer srv.Close()
+
+	c := metar.Client{
+		BaseURL:     srv.URL,
+		BatchSize:   2,
+		Concurrency: 2,
+	}
+
+	ids := []string{"KAAA", "KBBB", "KCCC", "FAIL", "KDDD", "KEEE", "KFFF", "KAAA"}
+
+	out, err := c.GetMETARs(context.Background(), ids...)
+
+	var be *metar.BatchError
+	if !errors.As(err, &be) {
+		t.Fatalf("expected batch error, got %v", err)
+	}
+
+	if failed := be.FailedAirportIDs(); len(failed) != 2 || failed[0] != "KCCC" || failed[1] != "FAIL" {
+		t.Fatalf("expected KCCC,FAIL to fail, got %v", failed)
+	}
+
+	for _, id := range []string{"KAAA", "KBBB", "KDDD", "KEEE", "KFFF"} {
+		if _, ok := out[id]; !ok {
+			t.Fatalf("expected %s in results", id)
+		}
+	}
+
+	if n := calls.Load(); n != 4 {
+		t.Fatalf("expected 4 requests, got %d", n)
+	}
+
+	if n := maxInFlight.Load(); n > 2 {
+		t.Fatalf("expected at most 2 concurrent requests, got %d", n)
+	}
+}
+
+func TestGetMETARsFormats(t *testing.T) {
+	type fixture struct {
+		format metar.Format
+		file   string
+	}
+
+	fixtures := []fixture{
+		{format: metar.FormatXML, file: "testdata/metars-2024-04-14.xml"},
+		{format: metar.FormatCSV, file: "testdata/metars-2024-04-14.csv"},
+	}
+
+	for _, fix := range fixtures {
+		t.Run(string(fix.format), func(t *testing.T) {
+
+			bts, err := os.ReadFile(fix.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.URL.Query().Get("format"); got != string(fix.format) {
+					t.Errorf("expected %v, got %v", fix.format, got)
+				}
+				w.Write(bts)
+			}))
+			defer srv.Close()
+
+			c := metar.Client{
+				BaseURL: srv.URL,
+				Format:  fix.format,
+			}
+
+			out, err := c.GetMETARs(context.Background(), "KFIT", "KRNM")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(out) != 2 {
+				t.Fatalf("expected %v, got %v", 2, len(out))
+			}
+
+			// The newer of the two KFIT reports wins.
+			if exp := metar.Time(time.Date(2024, 4, 14, 13, 52, 0, 0, time.UTC)); out["KFIT"].ObservationTime != exp {
+				t.Fatalf("expected %v, got %v", exp, out["KFIT"].ObservationTime)
+			}
+			if exp := metar.FlightCategoryVFR; out["KFIT"].FlightCategory() != exp {
+				t.Fatalf("expected %v, got %v", exp, out["KFIT"].FlightCategory())
+			}
+			if exp := metar.FlightCategoryLIFR; out["KRNM"].FlightCategory() != exp {
+				t.Fatalf("expected %v, got %v", exp, out["KRNM"].FlightCategory())
+			}
+			if !out["KRNM"].Auto || out["KRNM"].WxString != "-RA BR" {
+				t.Fatalf("expected auto with -RA BR, got %+v", out["KRNM"])
+			}
+		})
+	}
+}
+
+func TestDecodeXMLErrors(t *testing.T) {
+
+	const body = `<response><errors><error>Query must be constrained by time</error></errors><data num_results="0"></data></response>`
+
+	_, err := metar.DecodeXML(strings.NewReader(body))
+	if err == nil || !strings.Contains(err.Error(), "constrained") {
+		t.Fatalf("expected response error, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package metar
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+// GradientStop is the color of a value in a Gradient.
+type GradientStop struct {
+	Value float64
+	Color ws2811.RGB
+}
+
+func (s GradientStop) String() string {
+	return strconv.FormatFloat(s.Value, 'f', -1, 64) + ":" + s.Color.String()
+}
+
+// Gradient maps values to colors, interpolating between stops ordered by
+// value.
+type Gradient []GradientStop
+
+// ParseGradient parses stops such as "-20:#0000ff" into a Gradient. Repeated
+// values make a step.
+func ParseGradient(stops []string) (Gradient, error) {
+
+	out := make(Gradient, 0, len(stops))
+
+	for _, s := range stops {
+		v, c, ok := strings.Cut(strings.TrimSpace(s), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid gradient stop, expected value:color: %s", s)
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gradient stop value %q: %w", v, err)
+		}
+		rgb, err := ws2811.ParseRGB(c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gradient stop color: %w", err)
+		}
+		out = append(out, GradientStop{Value: f, Color: rgb})
+	}
+
+	if len(out) == 0 {
+		return nil, fmt.Errorf("gradient has no stops")
+	}
+
+	slices.SortStableFunc(out, func(a, b GradientStop) int {
+		switch {
+		case a.Value < b.Value:
+			return -1
+		case a.Value > b.Value:
+			return 1
+		}
+		return 0
+	})
+
+	return out, nil
+}
+
+// Color returns the color of v.
+func (g Gradient) Color(v float64) ws2811.RGB {
+	if len(g) == 0 {
+		return ws2811.Off
+	}
+	if v <= g[0].Value {
+		return g[0].Color
+	}
+	for i := 1; i < len(g); i++ {
+		lo, hi := g[i-1], g[i]
+		if v > hi.Value {
+			continue
+		}
+		return ws2811.Mix(lo.Color, hi.Color, (v-lo.Value)/(hi.Value-lo.Value))
+	}
+	return g[len(g)-1].Color
+}
+
+func (g Gradient) String() string {
+	parts := make([]string, len(g))
+	for i, s := range g {
+		parts[i] = s.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// gradient returns the Gradient of the mode.
+func (srv *ColorServer) gradient() Gradient {
+	if srv.Gradient != nil {
+		return srv.Gradient
+	}
+	switch srv.Mode {
+	case ModeTemperature:
+		return DefaultTemperatureGradient
+	case ModeCeilingVisibility:
+		return DefaultConditionsGradient
+	case ModeWind:
+		return DefaultWindGradient
+	case ModeCrosswind:
+		return DefaultCrosswindGradient
+	case ModeDensityAltitude:
+		return DefaultDensityAltitudeGradient
+	case ModeFog:
+		return DefaultFogGradient
+	}
+	return nil
+}
+
+// gradientValue returns the value of m displayed by a gradient mode.
+func (srv *ColorServer) gradientValue(m METAR) (float64, bool) {
+	switch srv.Mode {
+	case ModeTemperature:
+		return m.Temperature, m.HasTemperature
+	case ModeCeilingVisibility:
+		return m.NormalizedConditions()
+	case ModeWind:
+		// Variable wind is colored by its speed like any other.
+		return m.WindSpeed, true
+	case ModeCrosswind:
+		return m.Crosswind(srv.Runways[m.ICAOID])
+	case ModeDensityAltitude:
+		return m.DensityAltitude()
+	case ModeFog:
+		return m.ProjectedSpread(srv.previous[m.ICAOID], srv.Fog.lookahead())
+	}
+	return 0, false
+}
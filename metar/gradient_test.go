@@ -0,0 +1,124 @@
+package metar_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+func TestParseGradient(t *testing.T) {
+
+	g, err := metar.ParseGradient([]string{"35:#ff0000", "-20:#0000ff", " 0:255,255,255"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := metar.DefaultTemperatureGradient.String(), g.String(); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	for _, stops := range [][]string{
+		nil,
+		{"-20"},
+		{"cold:#0000ff"},
+		{"-20:blue"},
+	} {
+		if _, err := metar.ParseGradient(stops); err == nil {
+			t.Fatalf("expected error for %q", stops)
+		}
+	}
+}
+
+func TestGradientColor(t *testing.T) {
+	type fixture struct {
+		v   float64
+		exp ws2811.RGB
+	}
+
+	g := metar.Gradient{
+		{Value: 0, Color: ws2811.RGB{}},
+		{Value: 10, Color: ws2811.RGB{Red: 255, Green: 255, Blue: 255}},
+		{Value: 20, Color: ws2811.RGB{Red: 255}},
+	}
+
+	fixtures := []fixture{
+		{v: -5, exp: ws2811.RGB{}},
+		{v: 0, exp: ws2811.RGB{}},
+		// Interpolated in OKLab, so the middle is perceptual gray.
+		{v: 5, exp: ws2811.RGB{Red: 99, Green: 99, Blue: 99}},
+		{v: 10, exp: ws2811.RGB{Red: 255, Green: 255, Blue: 255}},
+		{v: 20, exp: ws2811.RGB{Red: 255}},
+		{v: 40, exp: ws2811.RGB{Red: 255}},
+	}
+
+	for _, fix := range fixtures {
+		if got := g.Color(fix.v); got != fix.exp {
+			t.Fatalf("expected %v for %v, got %v", fix.exp, fix.v, got)
+		}
+	}
+}
+
+func TestColorServerTemperature(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"PPIT", "PAOU", "KCGS", "KXXX", "SBTU", "SBTE"},
+		LEDIndexByAirportID: map[string]int{
+			"PPIT": 0,
+			"PAOU": 1,
+			"KCGS": 2,
+			"KXXX": 3,
+			"SBTU": 4,
+			"SBTE": 5,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: 100000 * time.Hour,
+		Mode:       metar.ModeTemperature,
+		Minimums: &metar.Minimums{
+			Ceiling: 5000,
+		},
+	}
+
+	sts, err := srv.GetMETARs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	g := metar.DefaultTemperatureGradient
+
+	exp := map[int]ws2811.RGB{
+		// M02/M03
+		0: g.Color(-2),
+		// 01/M02, below the ceiling minimum but minimums are not shown.
+		1: g.Color(1),
+		// T02190018
+		2: g.Color(21.9),
+		3: metar.DefaultMissingColor,
+		// No temperature reported.
+		4: metar.DefaultColors[metar.FlightCategoryUnknown],
+		5: metar.DefaultColors[metar.FlightCategoryUnknown],
+	}
+
+	for _, idx := range []int{4, 5} {
+		if sts[idx].HasValue {
+			t.Fatalf("expected no value at %d, got %v", idx, sts[idx].Value)
+		}
+	}
+
+	colors := srv.StatusToRGB(sts)
+
+	for idx, c := range exp {
+		if colors[idx] != c {
+			t.Fatalf("expected %v at %d, got %v", c, idx, colors[idx])
+		}
+	}
+
+	if colors[0] == colors[1] {
+		t.Fatalf("expected different colors either side of freezing, got %v", colors[0])
+	}
+}
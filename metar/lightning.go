@@ -0,0 +1,90 @@
+package metar
+
+import (
+	"strings"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+const (
+	DefaultLightningFlash       = 80 * time.Millisecond
+	DefaultLightningMinInterval = 2 * time.Second
+	DefaultLightningMaxInterval = 8 * time.Second
+)
+
+var (
+	DefaultLightningColor = ws2811.RGB{
+		Red:   255,
+		Green: 255,
+		Blue:  255,
+	}
+)
+
+// Remarks returns the remarks of the raw observation, after "RMK".
+func (m METAR) Remarks() string {
+	raw := " " + m.RawObservation + " "
+	i := strings.Index(raw, " RMK ")
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(raw[i+len(" RMK "):])
+}
+
+// HasLightning reports whether the METAR reports a thunderstorm or lightning
+// in the remarks.
+func (m METAR) HasLightning() bool {
+	if m.HasThunderstorm() {
+		return true
+	}
+	for _, tok := range strings.Fields(m.Remarks()) {
+		if strings.HasPrefix(tok, "LTG") {
+			return true
+		}
+	}
+	return false
+}
+
+// LightningOptions configure flashes on airports reporting lightning.
+type LightningOptions struct {
+	// Color of a flash. Zero uses DefaultLightningColor.
+	Color ws2811.RGB
+	// Flash is how long a flash lasts. Zero uses DefaultLightningFlash.
+	Flash time.Duration
+	// MinInterval and MaxInterval bound the random time between flashes.
+	// Zero uses DefaultLightningMinInterval and DefaultLightningMaxInterval.
+	MinInterval time.Duration
+	MaxInterval time.Duration
+}
+
+func (o LightningOptions) color() ws2811.RGB {
+	if o.Color != (ws2811.RGB{}) {
+		return o.Color
+	}
+	return DefaultLightningColor
+}
+
+func (o LightningOptions) flash() time.Duration {
+	if o.Flash > 0 {
+		return o.Flash
+	}
+	return DefaultLightningFlash
+}
+
+// effect flashes base.
+func (o LightningOptions) effect(base ws2811.Effect) ws2811.Effect {
+	mn, mx := o.MinInterval, o.MaxInterval
+	if mn <= 0 {
+		mn = DefaultLightningMinInterval
+	}
+	if mx <= 0 {
+		mx = DefaultLightningMaxInterval
+	}
+	return &ws2811.Flash{
+		Base:        base,
+		Color:       o.color(),
+		Duration:    o.flash(),
+		MinInterval: mn,
+		MaxInterval: mx,
+	}
+}
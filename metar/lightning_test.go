@@ -0,0 +1,120 @@
+package metar_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+	"github.com/robfig/cron/v3"
+	ws281x "github.com/rpi-ws281x/rpi-ws281x-go"
+)
+
+func TestHasLightning(t *testing.T) {
+	type fixture struct {
+		raw string
+		exp bool
+	}
+
+	fixtures := []fixture{
+		{raw: "WMKL 141600Z 07003KT 360V110 9999 TS FEW017CB 30/26 Q1011", exp: true},
+		{raw: "KFIT 141352Z 26010KT 10SM VCTS BKN040 12/M01 A2985", exp: true},
+		{raw: "KDMW 141605Z AUTO 20011G18KT 1SM CLR 22/04 A2988 RMK AO2 LTG DSNT N", exp: true},
+		{raw: "KFIT 141352Z 26010KT 10SM BKN040 12/M01 A2985 RMK AO2 OCNL LTGICCG NW", exp: true},
+		{raw: "PPIT 141610Z AUTO 25010KT 10SM OVC030 M02/M03 A3029 RMK AO2 FZRANO TSNO"},
+		{raw: "KFIT 141352Z 26010KT 10SM -RA BKN040 12/M01 A2985 RMK AO2"},
+	}
+
+	ref := time.Date(2024, 4, 14, 18, 0, 0, 0, time.UTC)
+
+	for _, fix := range fixtures {
+		t.Run(fix.raw, func(t *testing.T) {
+			m, err := metar.ParseRawAt(fix.raw, ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.HasLightning(); got != fix.exp {
+				t.Fatalf("expected %v, got %v", fix.exp, got)
+			}
+		})
+	}
+}
+
+func TestColorServerLightning(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	flash := ws2811.RGB{Red: 1, Green: 2, Blue: 3}
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"WMKL", "KDMW", "PAOU"},
+		LEDIndexByAirportID: map[string]int{
+			"WMKL": 0,
+			"KDMW": 1,
+			"PAOU": 2,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: 100000 * time.Hour,
+		Lightning: &metar.LightningOptions{
+			Color:       flash,
+			Flash:       20 * time.Millisecond,
+			MinInterval: 30 * time.Millisecond,
+			MaxInterval: 60 * time.Millisecond,
+		},
+	}
+
+	rendered := make(chan []ws2811.RGB, 256)
+
+	ctrl := &ws2811.Controller{
+		Driver: &ws2811.Simulator{
+			OnRender: func(frame []ws2811.RGB) {
+				select {
+				case rendered <- frame:
+				default:
+				}
+			},
+		},
+		Options: []ws2811.Option{
+			func(opt *ws281x.ChannelOption) {
+				opt.LedCount = 3
+			},
+		},
+		FrameRate: 100,
+	}
+
+	scd, err := cron.ParseStandard("0 0 1 1 *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leds := make(chan ws2811.State)
+
+	go srv.Serve(ctx, scd, leds)
+	go ctrl.Serve(ctx, leds)
+
+	flashes := make(map[int]int)
+	deadline := time.After(time.Second)
+
+	for flashes[0] < 2 || flashes[1] < 2 {
+		select {
+		case frame := <-rendered:
+			for i, c := range frame {
+				if c == flash {
+					flashes[i]++
+				}
+			}
+		case <-deadline:
+			t.Fatalf("expected flashes at 0 and 1, got %v", flashes)
+		}
+	}
+
+	if flashes[2] > 0 {
+		t.Fatalf("expected no flashes at 2, got %v", flashes[2])
+	}
+}
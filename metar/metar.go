@@ -37,6 +37,31 @@ func (c FlightCategory) Name() string {
 	return "Unknown"
 }
 
+// Abbreviation returns the short name of the category, e.g. "MVFR".
+func (c FlightCategory) Abbreviation() string {
+	switch c {
+	case FlightCategoryVFR:
+		return "VFR"
+	case FlightCategoryMVFR:
+		return "MVFR"
+	case FlightCategoryIFR:
+		return "IFR"
+	case FlightCategoryLIFR:
+		return "LIFR"
+	}
+	return "UNKNOWN"
+}
+
+// ParseFlightCategory parses a category abbreviation such as "MVFR".
+func ParseFlightCategory(s string) (FlightCategory, bool) {
+	for _, c := range []FlightCategory{FlightCategoryLIFR, FlightCategoryIFR, FlightCategoryMVFR, FlightCategoryVFR} {
+		if strings.EqualFold(strings.TrimSpace(s), c.Abbreviation()) {
+			return c, true
+		}
+	}
+	return FlightCategoryUnknown, false
+}
+
 func (c FlightCategory) IsWorseThan(oth FlightCategory) bool {
 	return c < oth
 }
@@ -77,21 +102,75 @@ type METAR struct {
 	Prior                 float64       `json:"prior"`
 	Name                  string        `json:"name"`
 	Clouds                []CloudLayer  `json:"clouds"`
+
+	// HasTemperature, HasDewpoint and HasElevation are set when the values
+	// are reported.
+	HasTemperature bool `json:"-"`
+	HasDewpoint    bool `json:"-"`
+	HasElevation   bool `json:"-"`
+
+	// Fields below are only decoded from raw observations.
+	Auto               bool                `json:"auto,omitempty"`
+	Corrected          bool                `json:"corrected,omitempty"`
+	WindVariability    *WindVariability    `json:"wdirVar,omitempty"`
+	RunwayVisualRanges []RunwayVisualRange `json:"rvr,omitempty"`
 }
 
+// FlightCategory returns the flight category of the METAR by FAARules.
 func (m METAR) FlightCategory() FlightCategory {
-	vis := m.Visibility
-	if vis == nil {
-		return FlightCategoryUnknown
+	return FAARules.FlightCategory(m)
+}
+
+// metarJSON is a METAR without its JSON methods.
+type metarJSON METAR
+
+func (m *METAR) UnmarshalJSON(b []byte) error {
+	aux := struct {
+		*metarJSON
+		Temperature *float64 `json:"temp"`
+		Dewpoint    *float64 `json:"dewp"`
+		Elevation   *float64 `json:"elev"`
+	}{metarJSON: (*metarJSON)(m)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
 	}
-	out := vis.FlightCategory()
-	for _, lyr := range m.Clouds {
-		c := lyr.FlightCategory()
-		if c.IsWorseThan(out) {
-			out = c
-		}
+	m.Temperature, m.HasTemperature = reported(aux.Temperature)
+	m.Dewpoint, m.HasDewpoint = reported(aux.Dewpoint)
+	m.Elevation, m.HasElevation = reported(aux.Elevation)
+	return nil
+}
+
+func (m METAR) MarshalJSON() ([]byte, error) {
+	aux := struct {
+		metarJSON
+		Temperature *float64 `json:"temp"`
+		Dewpoint    *float64 `json:"dewp"`
+		Elevation   *float64 `json:"elev"`
+	}{metarJSON: metarJSON(m)}
+	if m.HasTemperature {
+		aux.Temperature = &m.Temperature
 	}
-	return out
+	if m.HasDewpoint {
+		aux.Dewpoint = &m.Dewpoint
+	}
+	if m.HasElevation {
+		aux.Elevation = &m.Elevation
+	}
+	return json.Marshal(aux)
+}
+
+func reported(f *float64) (float64, bool) {
+	if f == nil {
+		return 0, false
+	}
+	return *f, true
+}
+
+// Ceiling returns the height of the lowest broken, overcast or obscured
+// layer. It returns false when there is no ceiling of known height.
+func (m METAR) Ceiling() (float64, bool) {
+	c, ok, known := ceiling(m.Clouds, m.VerticalVisibility)
+	return c, ok && known
 }
 
 type CloudCover string
@@ -103,6 +182,10 @@ const (
 	CloudCoverBroken    = "BKN"
 	CloudCoverOvercast  = "OVC"
 	CloudCoverObscured  = "OVX"
+	CloudCoverSkyClear  = "SKC"
+	CloudCoverNSC       = "NSC"
+	CloudCoverNCD       = "NCD"
+	CloudCoverCAVOK     = "CAVOK"
 )
 
 func (m CloudCover) String() string {
@@ -131,6 +214,14 @@ func (m CloudCover) Name() string {
 		return "Overcast"
 	case CloudCoverObscured:
 		return "Obscured"
+	case CloudCoverSkyClear:
+		return "Sky Clear"
+	case CloudCoverNSC:
+		return "No Significant Cloud"
+	case CloudCoverNCD:
+		return "No Cloud Detected"
+	case CloudCoverCAVOK:
+		return "Ceiling and Visibility OK"
 	}
 	return "Unknown"
 }
@@ -140,6 +231,8 @@ type CloudLayer struct {
 	Base  *float64   `json:"base"`
 }
 
+// FlightCategory returns the flight category of the layer's ceiling by
+// FAARules, ignoring visibility.
 func (lyr CloudLayer) FlightCategory() FlightCategory {
 	if !lyr.Cover.IsCeiling() {
 		return FlightCategoryVFR
@@ -147,16 +240,12 @@ func (lyr CloudLayer) FlightCategory() FlightCategory {
 	if lyr.Base == nil {
 		return FlightCategoryUnknown
 	}
-	base := *lyr.Base
-	switch {
-	case base > 3000:
-		return FlightCategoryVFR
-	case base > 1000:
-		return FlightCategoryMVFR
-	case base > 500:
-		return FlightCategoryIFR
+	for _, t := range FAARules.Thresholds {
+		if t.Ceiling.contains(*lyr.Base) {
+			return t.Category
+		}
 	}
-	return FlightCategoryLIFR
+	return FlightCategoryVFR
 }
 
 type METARType string
@@ -169,6 +258,7 @@ const (
 type Visibility struct {
 	Visibility  float64 `json:"vis"`
 	GreaterThan bool    `json:"gt"`
+	LessThan    bool    `json:"lt"`
 }
 
 func (v *Visibility) UnmarshalJSON(b []byte) error {
@@ -189,6 +279,10 @@ func (v *Visibility) UnmarshalJSON(b []byte) error {
 		v.GreaterThan = true
 		s = strings.TrimSuffix(s, "+")
 	}
+	if strings.HasPrefix(s, "M") {
+		v.LessThan = true
+		s = strings.TrimPrefix(s, "M")
+	}
 	f, err := strconv.ParseFloat(s, 64)
 	if err != nil {
 		return &json.UnmarshalTypeError{
@@ -205,6 +299,9 @@ func (v Visibility) MarshalJSON() ([]byte, error) {
 	if v.GreaterThan {
 		return []byte(`"` + s + "+" + `"`), nil
 	}
+	if v.LessThan {
+		return []byte(`"M` + s + `"`), nil
+	}
 	return []byte(s), nil
 }
 
@@ -213,18 +310,37 @@ func (v Visibility) String() string {
 	if v.GreaterThan {
 		return vis + "+"
 	}
+	if v.LessThan {
+		return "M" + vis
+	}
 	return vis
 }
 
+// FlightCategory returns the flight category of the visibility by FAARules,
+// ignoring ceiling.
 func (v Visibility) FlightCategory() FlightCategory {
-	if v.Visibility > 5 {
-		return FlightCategoryVFR
-	} else if v.Visibility >= 3 {
-		return FlightCategoryMVFR
-	} else if v.Visibility >= 1 {
-		return FlightCategoryIFR
+	for _, t := range FAARules.Thresholds {
+		if t.Visibility.containsVisibility(v) {
+			return t.Category
+		}
 	}
-	return FlightCategoryLIFR
+	return FlightCategoryVFR
+}
+
+// WindVariability is the sector the wind direction varies across, e.g. 180V240.
+type WindVariability struct {
+	From int32 `json:"fm"`
+	To   int32 `json:"to"`
+}
+
+// RunwayVisualRange is a reported runway visual range in feet.
+type RunwayVisualRange struct {
+	Runway      string  `json:"rwy"`
+	Visibility  float64 `json:"vis"`
+	VariableTo  float64 `json:"varTo,omitempty"`
+	GreaterThan bool    `json:"gt,omitempty"`
+	LessThan    bool    `json:"lt,omitempty"`
+	Trend       string  `json:"trend,omitempty"`
 }
 
 type Time time.Time
@@ -259,6 +375,19 @@ func (t *Time) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// After reports whether t is after u.
+func (t Time) After(u Time) bool {
+	return time.Time(t).After(time.Time(u))
+}
+
+func (t Time) MarshalJSON() ([]byte, error) {
+	tt := time.Time(t)
+	if tt.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(tt.Unix())
+}
+
 type WindDirection struct {
 	From     int32 `json:"fm"`
 	Variable bool  `json:"vrb"`
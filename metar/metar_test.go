@@ -511,6 +511,32 @@ func TestUnmarshalMETAR(t *testing.T) {
 	}
 }
 
+func TestMETARJSONReportedTemperature(t *testing.T) {
+
+	var m metar.METAR
+	if err := json.Unmarshal([]byte(`{"icaoId":"SBTE","temp":null,"dewp":-2}`), &m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if m.HasTemperature || !m.HasDewpoint || m.Dewpoint != -2 {
+		t.Fatalf("expected only a dewpoint of -2, got %+v", m)
+	}
+
+	bts, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got metar.METAR
+	if err := json.Unmarshal(bts, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.HasTemperature != m.HasTemperature || got.HasDewpoint != m.HasDewpoint || got.Dewpoint != m.Dewpoint {
+		t.Fatalf("expected %+v, got %+v", m, got)
+	}
+}
+
 func TestUnmarshalMETARHandlesEverything(t *testing.T) {
 
 	bts, err := os.ReadFile("testdata/2024-04-14.json")
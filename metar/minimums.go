@@ -0,0 +1,86 @@
+package metar
+
+import (
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+var (
+	DefaultMinimumsColor = ws2811.RGB{
+		Red:   255,
+		Green: 128,
+		Blue:  0,
+	}
+)
+
+// Minimums are a pilot's personal minimums. Zero fields are not checked.
+type Minimums struct {
+	// Ceiling is the lowest ceiling in feet above ground level.
+	Ceiling float64
+	// Visibility is the lowest visibility in statute miles.
+	Visibility float64
+	// Crosswind is the strongest crosswind in knots, gusts included.
+	Crosswind float64
+}
+
+// IsZero reports whether no minimums are set.
+func (p Minimums) IsZero() bool {
+	return p == Minimums{}
+}
+
+// Below returns which of the minimums the conditions are below: "ceiling",
+// "visibility" or "crosswind".
+func (p Minimums) Below(m METAR, crosswind float64) []string {
+
+	var out []string
+
+	if p.Ceiling > 0 {
+		if c, ok := m.Ceiling(); ok && c < p.Ceiling {
+			out = append(out, "ceiling")
+		}
+	}
+
+	if p.Visibility > 0 && m.Visibility != nil {
+		v := *m.Visibility
+		if v.Visibility < p.Visibility || (v.LessThan && v.Visibility <= p.Visibility) {
+			out = append(out, "visibility")
+		}
+	}
+
+	if p.Crosswind > 0 && crosswind > p.Crosswind {
+		out = append(out, "crosswind")
+	}
+
+	return out
+}
+
+// MaxWind returns the wind speed, or the gust speed when gusts are reported,
+// in knots.
+func (m METAR) MaxWind() float64 {
+	if m.WindGust > m.WindSpeed {
+		return m.WindGust
+	}
+	return m.WindSpeed
+}
+
+// crosswind returns the crosswind, or the whole wind without runways.
+func (srv *ColorServer) crosswind(m METAR) float64 {
+	if xw, ok := m.Crosswind(srv.Runways[m.ICAOID]); ok {
+		return xw
+	}
+	return m.MaxWind()
+}
+
+// belowMinimums returns the minimums a VFR airport is below.
+func (srv *ColorServer) belowMinimums(m METAR, fc FlightCategory) []string {
+	if srv.Minimums == nil || fc != FlightCategoryVFR || srv.Mode.IsGradient() {
+		return nil
+	}
+	return srv.Minimums.Below(m, srv.crosswind(m))
+}
+
+func (srv *ColorServer) minimumsColor() ws2811.RGB {
+	if c := srv.MinimumsColor; c != nil {
+		return *c
+	}
+	return DefaultMinimumsColor
+}
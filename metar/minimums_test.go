@@ -0,0 +1,106 @@
+package metar_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+func TestMinimumsBelow(t *testing.T) {
+	type fixture struct {
+		raw       string
+		crosswind float64
+		exp       []string
+	}
+
+	mins := metar.Minimums{
+		Ceiling:    5000,
+		Visibility: 7,
+		Crosswind:  15,
+	}
+
+	fixtures := []fixture{
+		{raw: "KFIT 141352Z 26010KT 10SM SCT040 BKN060 12/M01 A2985"},
+		{raw: "KFIT 141352Z 26010KT 10SM BKN045 12/M01 A2985", exp: []string{"ceiling"}},
+		{raw: "KFIT 141352Z 26010KT 6SM BKN060 12/M01 A2985", exp: []string{"visibility"}},
+		{raw: "KFIT 141352Z 26010G22KT 6SM VV040 12/M01 A2985", crosswind: 22, exp: []string{"ceiling", "visibility", "crosswind"}},
+		{raw: "KFIT 141352Z 26010KT 7SM CLR 12/M01 A2985", crosswind: 15},
+	}
+
+	ref := time.Date(2024, 4, 14, 18, 0, 0, 0, time.UTC)
+
+	for _, fix := range fixtures {
+		t.Run(fix.raw, func(t *testing.T) {
+			m, err := metar.ParseRawAt(fix.raw, ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exp, got := strings.Join(fix.exp, ","), strings.Join(mins.Below(m, fix.crosswind), ","); exp != got {
+				t.Fatalf("expected %v, got %v", exp, got)
+			}
+		})
+	}
+}
+
+func TestColorServerMinimums(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"PAOU", "KCGS", "KUKF", "CYYL"},
+		LEDIndexByAirportID: map[string]int{
+			"PAOU": 0,
+			"KCGS": 1,
+			"KUKF": 2,
+			"CYYL": 3,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: 100000 * time.Hour,
+		Minimums: &metar.Minimums{
+			Ceiling:   5000,
+			Crosswind: 15,
+		},
+	}
+
+	sts, err := srv.GetMETARs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exp := map[int]metar.Status{
+		// OVC038
+		0: {FlightCategory: metar.FlightCategoryVFR, BelowMinimums: true},
+		// 18008G19KT
+		1: {FlightCategory: metar.FlightCategoryVFR, BelowMinimums: true},
+		2: {FlightCategory: metar.FlightCategoryVFR},
+		// Only VFR airports are checked.
+		3: {FlightCategory: metar.FlightCategoryIFR},
+	}
+
+	for idx, st := range exp {
+		if sts[idx] != st {
+			t.Fatalf("expected %v at %d, got %v", st, idx, sts[idx])
+		}
+	}
+
+	colors := srv.StatusToRGB(sts)
+
+	expColors := map[int]ws2811.RGB{
+		0: metar.DefaultMinimumsColor,
+		1: metar.DefaultMinimumsColor,
+		2: metar.DefaultColors[metar.FlightCategoryVFR],
+		3: metar.DefaultColors[metar.FlightCategoryIFR],
+	}
+
+	for idx, c := range expColors {
+		if colors[idx] != c {
+			t.Fatalf("expected %v at %d, got %v", c, idx, colors[idx])
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package metar
+
+import (
+	"context"
+)
+
+// Provider is a source of METARs.
+type Provider interface {
+	// GetMETARs gets the latest METAR for each airport it has one for.
+	GetMETARs(ctx context.Context, airportIDs ...string) (map[string]METAR, error)
+}
+
+// HistoryProvider is a source of earlier METARs.
+type HistoryProvider interface {
+	// GetMETARHistory gets each airport's METARs from the last hours, newest
+	// first.
+	GetMETARHistory(ctx context.Context, hours int, airportIDs ...string) (map[string][]METAR, error)
+}
+
+// ProviderFunc adapts a function to a Provider.
+type ProviderFunc func(ctx context.Context, airportIDs ...string) (map[string]METAR, error)
+
+func (f ProviderFunc) GetMETARs(ctx context.Context, airportIDs ...string) (map[string]METAR, error) {
+	return f(ctx, airportIDs...)
+}
+
+// latest adds m to metars unless there is a newer observation.
+func latest(metars map[string]METAR, m METAR) {
+	if prev, ok := metars[m.ICAOID]; ok && !Time.After(m.ObservationTime, prev.ObservationTime) {
+		return
+	}
+	metars[m.ICAOID] = m
+}
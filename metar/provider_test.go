@@ -0,0 +1,219 @@
+package metar_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+)
+
+func TestDecodeCSV(t *testing.T) {
+
+	f, err := os.Open("testdata/metars-2024-04-14.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	metars, err := metar.DecodeCSV(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(metars) != 3 {
+		t.Fatalf("expected %v, got %v", 3, len(metars))
+	}
+
+	fit := metars[0]
+	if fit.ICAOID != "KFIT" || !fit.Auto || fit.WindGust != 15 {
+		t.Fatalf("expected KFIT auto with gusts, got %+v", fit)
+	}
+	if exp := metar.Time(time.Date(2024, 4, 14, 13, 52, 0, 0, time.UTC)); fit.ObservationTime != exp {
+		t.Fatalf("expected %v, got %v", exp, fit.ObservationTime)
+	}
+	if fit.Visibility == nil || fit.Visibility.String() != "10+" {
+		t.Fatalf("expected %v, got %v", "10+", fit.Visibility)
+	}
+	if fit.Altimeter != 1010.8 {
+		t.Fatalf("expected %v, got %v", 1010.8, fit.Altimeter)
+	}
+	if !fit.HasTemperature || fit.Temperature != 11.7 {
+		t.Fatalf("expected %v, got %v (%v)", 11.7, fit.Temperature, fit.HasTemperature)
+	}
+
+	rnm := metars[1]
+	if rnm.MetarType != metar.METARTypeSpecial || rnm.WxString != "-RA BR" {
+		t.Fatalf("expected SPECI with -RA BR, got %+v", rnm)
+	}
+	if rnm.VerticalVisibility == nil || *rnm.VerticalVisibility != 200 {
+		t.Fatalf("expected %v, got %v", 200, rnm.VerticalVisibility)
+	}
+	if len(rnm.Clouds) != 1 || rnm.Clouds[0].Cover != metar.CloudCoverObscured {
+		t.Fatalf("expected OVX layer, got %+v", rnm.Clouds)
+	}
+
+	for i, exp := range []metar.FlightCategory{metar.FlightCategoryVFR, metar.FlightCategoryLIFR, metar.FlightCategoryMVFR} {
+		if got := metars[i].FlightCategory(); got != exp {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	}
+}
+
+func TestDecodeCSVMissingHeader(t *testing.T) {
+	if _, err := metar.DecodeCSV(strings.NewReader("No errors\nNo warnings\n")); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestTGFTPProvider(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/stations/KFIT.TXT":
+			w.Write([]byte("2024/04/14 13:52\nKFIT 141352Z AUTO 26010G15KT 10SM CLR 12/M01 A2985 RMK AO2\n"))
+		case "/stations/KRNM.TXT":
+			w.Write([]byte("2024/04/14 14:48\nKRNM 141448Z AUTO 00000KT 1 1/2SM -RA BR VV002 07/07\n A3015 RMK AO2\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	p := metar.TGFTPProvider{BaseURL: srv.URL + "/stations"}
+
+	metars, err := p.GetMETARs(context.Background(), "KFIT", "KRNM", "KXXX")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp, got := []string{"KFIT", "KRNM"}, sortedIDs(metars); strings.Join(exp, ",") != strings.Join(got, ",") {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	if exp := metar.Time(time.Date(2024, 4, 14, 14, 48, 0, 0, time.UTC)); metars["KRNM"].ObservationTime != exp {
+		t.Fatalf("expected %v, got %v", exp, metars["KRNM"].ObservationTime)
+	}
+	if metars["KRNM"].Altimeter != 1021 {
+		t.Fatalf("expected wrapped report to be joined, got %+v", metars["KRNM"])
+	}
+}
+
+func TestTGFTPProviderMissingStationsKeepBreakerClosed(t *testing.T) {
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stations/KFIT.TXT" {
+			w.Write([]byte("2024/04/14 13:52\nKFIT 141352Z AUTO 26010G15KT 10SM CLR 12/M01 A2985 RMK AO2\n"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	p := metar.TGFTPProvider{
+		BaseURL: srv.URL + "/stations",
+		Client: metar.Client{
+			Breaker: &metar.CircuitBreaker{Threshold: 2},
+			// One at a time so the missing stations are requested first.
+			Concurrency: 1,
+		},
+	}
+
+	metars, err := p.GetMETARs(context.Background(), "KXA1", "KXA2", "KXA3", "KFIT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if exp, got := []string{"KFIT"}, sortedIDs(metars); strings.Join(exp, ",") != strings.Join(got, ",") {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	if p.Client.Breaker.IsOpen() {
+		t.Fatal("expected breaker closed")
+	}
+}
+
+func TestBulkCSVProvider(t *testing.T) {
+
+	raw, err := os.ReadFile("testdata/metars-2024-04-14.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(raw)
+	gz.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	p := metar.BulkCSVProvider{URL: srv.URL}
+
+	metars, err := p.GetMETARs(context.Background(), "KFIT", "KXXX")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp, got := []string{"KFIT"}, sortedIDs(metars); strings.Join(exp, ",") != strings.Join(got, ",") {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	// The newer of the two KFIT reports wins.
+	if exp := metar.Time(time.Date(2024, 4, 14, 13, 52, 0, 0, time.UTC)); metars["KFIT"].ObservationTime != exp {
+		t.Fatalf("expected %v, got %v", exp, metars["KFIT"].ObservationTime)
+	}
+}
+
+func TestDirectoryProvider(t *testing.T) {
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"KFIT.TXT": "2024/04/14 13:52\nKFIT 141352Z AUTO 26010G15KT 10SM CLR 12/M01 A2985 RMK AO2\n",
+		"mixed.txt": strings.Join([]string{
+			"2024/04/14 15:00",
+			"KFIT 141252Z AUTO 27008KT 10SM BKN015 11/M01 A2983 RMK AO2",
+			"not a metar",
+			"KRNM 141448Z AUTO 00000KT 1 1/2SM -RA BR VV002 07/07 A3015 RMK AO2",
+		}, "\n"),
+		".hidden": "KXXX 141448Z AUTO 00000KT 10SM CLR 07/07 A3015",
+		"bad.xml": "<response><data><METAR><station_id>KXXX",
+		"bad.csv": "station_id,observation_time\nKXXX,yesterday\n",
+	}
+	for name, s := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(s), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := metar.DirectoryProvider{Dir: dir}
+
+	metars, err := p.GetMETARs(context.Background(), "KFIT", "KRNM", "KXXX")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp, got := []string{"KFIT", "KRNM"}, sortedIDs(metars); strings.Join(exp, ",") != strings.Join(got, ",") {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	if exp := metar.FlightCategoryVFR; metars["KFIT"].FlightCategory() != exp {
+		t.Fatalf("expected %v, got %v", exp, metars["KFIT"].FlightCategory())
+	}
+}
+
+func sortedIDs(metars map[string]metar.METAR) []string {
+	ids := make([]string, 0, len(metars))
+	for id := range metars {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,348 @@
+package metar
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	metersPerStatuteMile = 1609.344
+	feetPerMeter         = 3.28084
+	hectopascalsPerInHg  = 33.8639
+	knotsPerMPS          = 1.943844
+	knotsPerKMH          = 0.539957
+)
+
+// ErrNilReport is returned by ParseRaw for a METAR reported as NIL.
+var ErrNilReport = errors.New("nil report")
+
+var (
+	rawTimeRe       = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	rawWindRe       = regexp.MustCompile(`^(\d{3}|VRB|///)(\d{2,3}|//)(?:G(\d{2,3}))?(KT|MPS|KMH)$`)
+	rawWindVarRe    = regexp.MustCompile(`^(\d{3})V(\d{3})$`)
+	rawVisSMRe      = regexp.MustCompile(`^([MP])?(?:(\d+)|(\d+)/(\d+))SM$`)
+	rawVisWholeRe   = regexp.MustCompile(`^\d$`)
+	rawVisFracRe    = regexp.MustCompile(`^(\d+)/(\d+)SM$`)
+	rawVisMetricRe  = regexp.MustCompile(`^(\d{4})(NDV)?$`)
+	rawRVRRe        = regexp.MustCompile(`^R(\d{2}[LCR]?)/([MP])?(\d{4})(?:V([MP])?(\d{4}))?(FT)?/?([UDN])?$`)
+	rawWeatherRe    = regexp.MustCompile(`^(?:-|\+|VC)?(?:(?:MI|PR|BC|DR|BL|SH|TS|FZ)+(?:DZ|RA|SN|SG|IC|PL|GR|GS|UP|BR|FG|FU|VA|DU|SA|HZ|PY|PO|SQ|FC|SS|DS)*|(?:DZ|RA|SN|SG|IC|PL|GR|GS|UP|BR|FG|FU|VA|DU|SA|HZ|PY|PO|SQ|FC|SS|DS)+)$`)
+	rawSkyRe        = regexp.MustCompile(`^(?:(?:FEW|SCT|BKN|OVC|VV)(?:\d{3}|///)(?:CB|TCU|///)?)+$`)
+	rawSkyLayerRe   = regexp.MustCompile(`(FEW|SCT|BKN|OVC|VV)(\d{3}|///)(?:CB|TCU|///)?`)
+	rawTempRe       = regexp.MustCompile(`^(M?\d{2})/(M?\d{2})?$`)
+	rawAltimeterRe  = regexp.MustCompile(`^([AQ])(\d{4})$`)
+	rawTrendOrRmkRe = regexp.MustCompile(`^(RMK|NOSIG|BECMG|TEMPO)$`)
+)
+
+// ParseRaw decodes the body of a raw METAR or SPECI report. The day of month
+// in the report is resolved against the current time.
+func ParseRaw(raw string) (METAR, error) {
+	return ParseRawAt(raw, time.Now().UTC())
+}
+
+// ParseRawAt decodes the body of a raw METAR or SPECI report, resolving the
+// day of month in the report to the most recent matching date at or before ref.
+func ParseRawAt(raw string, ref time.Time) (METAR, error) {
+
+	raw = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(raw), "="))
+
+	m := METAR{
+		MetarType:      METARTypeMETAR,
+		RawObservation: raw,
+	}
+
+	toks := strings.Fields(raw)
+
+	if len(toks) > 0 && (toks[0] == METARTypeMETAR || toks[0] == METARTypeSpecial) {
+		m.MetarType = METARType(toks[0])
+		toks = toks[1:]
+	}
+
+	if len(toks) > 0 && toks[0] == "COR" {
+		m.Corrected = true
+		toks = toks[1:]
+	}
+
+	if len(toks) == 0 {
+		return METAR{}, fmt.Errorf("missing station identifier")
+	}
+	m.ICAOID = toks[0]
+	toks = toks[1:]
+
+	if len(toks) == 0 {
+		return METAR{}, fmt.Errorf("missing observation time")
+	}
+	obs, err := parseRawTime(toks[0], ref)
+	if err != nil {
+		return METAR{}, err
+	}
+	m.ObservationTime = Time(obs)
+	toks = toks[1:]
+
+	var (
+		visibilityDone bool
+		weather        []string
+	)
+
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+
+		if rawTrendOrRmkRe.MatchString(tok) {
+			break
+		}
+
+		switch {
+		case tok == "NIL":
+			return METAR{}, ErrNilReport
+
+		case tok == "AUTO":
+			m.Auto = true
+
+		case tok == "COR":
+			m.Corrected = true
+
+		case rawWindRe.MatchString(tok):
+			if err := parseRawWind(&m, rawWindRe.FindStringSubmatch(tok)); err != nil {
+				return METAR{}, fmt.Errorf("invalid wind %q: %w", tok, err)
+			}
+
+		case rawWindVarRe.MatchString(tok):
+			sm := rawWindVarRe.FindStringSubmatch(tok)
+			from, _ := strconv.Atoi(sm[1])
+			to, _ := strconv.Atoi(sm[2])
+			m.WindVariability = &WindVariability{
+				From: int32(from),
+				To:   int32(to),
+			}
+
+		case tok == "CAVOK":
+			m.Visibility = &Visibility{Visibility: 6, GreaterThan: true}
+			m.Clouds = append(m.Clouds, CloudLayer{Cover: CloudCoverCAVOK})
+			visibilityDone = true
+
+		case !visibilityDone && rawVisWholeRe.MatchString(tok) && i+1 < len(toks) && rawVisFracRe.MatchString(toks[i+1]):
+			whole, _ := strconv.ParseFloat(tok, 64)
+			sm := rawVisFracRe.FindStringSubmatch(toks[i+1])
+			frac, err := parseFraction(sm[1], sm[2])
+			if err != nil {
+				return METAR{}, fmt.Errorf("invalid visibility %q: %w", tok+" "+toks[i+1], err)
+			}
+			m.Visibility = &Visibility{Visibility: whole + frac}
+			visibilityDone = true
+			i++
+
+		case !visibilityDone && rawVisSMRe.MatchString(tok):
+			vis, err := parseRawVisibilitySM(rawVisSMRe.FindStringSubmatch(tok))
+			if err != nil {
+				return METAR{}, fmt.Errorf("invalid visibility %q: %w", tok, err)
+			}
+			m.Visibility = vis
+			visibilityDone = true
+
+		case !visibilityDone && rawVisMetricRe.MatchString(tok):
+			meters, _ := strconv.ParseFloat(rawVisMetricRe.FindStringSubmatch(tok)[1], 64)
+			m.Visibility = metersToVisibility(meters)
+			visibilityDone = true
+
+		case rawRVRRe.MatchString(tok):
+			m.RunwayVisualRanges = append(m.RunwayVisualRanges, parseRawRVR(rawRVRRe.FindStringSubmatch(tok)))
+
+		case tok == "SKC" || tok == "CLR":
+			m.Clouds = append(m.Clouds, CloudLayer{Cover: CloudCover(tok)})
+
+		case tok == "NSC" || tok == "NCD":
+			m.Clouds = append(m.Clouds, CloudLayer{Cover: CloudCover(tok)})
+
+		case rawSkyRe.MatchString(tok):
+			// Layers are occasionally reported without a separating space.
+			for _, sm := range rawSkyLayerRe.FindAllStringSubmatch(tok, -1) {
+				m.Clouds = append(m.Clouds, parseRawSkyLayer(&m, sm))
+			}
+
+		case rawTempRe.MatchString(tok):
+			sm := rawTempRe.FindStringSubmatch(tok)
+			m.Temperature, m.HasTemperature = parseRawTemperature(sm[1]), true
+			if sm[2] != "" {
+				m.Dewpoint, m.HasDewpoint = parseRawTemperature(sm[2]), true
+			}
+
+		case rawAltimeterRe.MatchString(tok):
+			sm := rawAltimeterRe.FindStringSubmatch(tok)
+			v, _ := strconv.ParseFloat(sm[2], 64)
+			if sm[1] == "A" {
+				m.Altimeter = math.Round(v/100*hectopascalsPerInHg*10) / 10
+			} else {
+				m.Altimeter = v
+			}
+
+		case tok != "" && rawWeatherRe.MatchString(tok):
+			weather = append(weather, tok)
+		}
+	}
+
+	m.WxString = strings.Join(weather, " ")
+
+	return m, nil
+}
+
+func parseRawTime(tok string, ref time.Time) (time.Time, error) {
+	sm := rawTimeRe.FindStringSubmatch(tok)
+	if sm == nil {
+		return time.Time{}, fmt.Errorf("invalid observation time: %q", tok)
+	}
+
+	day, _ := strconv.Atoi(sm[1])
+	hour, _ := strconv.Atoi(sm[2])
+	min, _ := strconv.Atoi(sm[3])
+
+	if day < 1 || day > 31 || hour > 23 || min > 59 {
+		return time.Time{}, fmt.Errorf("invalid observation time: %q", tok)
+	}
+
+	ref = ref.UTC()
+	first := time.Date(ref.Year(), ref.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	// Allow for a little clock skew before assuming the previous month.
+	limit := ref.Add(time.Hour)
+
+	for i := 0; i < 3; i++ {
+		mo := first.AddDate(0, -i, 0)
+		t := time.Date(mo.Year(), mo.Month(), day, hour, min, 0, 0, time.UTC)
+		if t.Month() != mo.Month() || t.After(limit) {
+			continue
+		}
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unable to resolve observation time: %q", tok)
+}
+
+func parseRawWind(m *METAR, sm []string) error {
+	if sm[1] == "///" || sm[2] == "//" {
+		return nil
+	}
+
+	if sm[1] == "VRB" {
+		m.WindDirection = WindDirection{Variable: true}
+	} else {
+		dir, _ := strconv.Atoi(sm[1])
+		m.WindDirection = WindDirection{From: int32(dir)}
+	}
+
+	factor := 1.0
+	switch sm[4] {
+	case "MPS":
+		factor = knotsPerMPS
+	case "KMH":
+		factor = knotsPerKMH
+	}
+
+	spd, _ := strconv.ParseFloat(sm[2], 64)
+	m.WindSpeed = math.Round(spd * factor)
+
+	if sm[3] != "" {
+		gst, _ := strconv.ParseFloat(sm[3], 64)
+		m.WindGust = math.Round(gst * factor)
+	}
+
+	return nil
+}
+
+func parseFraction(num string, den string) (float64, error) {
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil {
+		return 0, err
+	}
+	if d == 0 {
+		return 0, fmt.Errorf("zero denominator")
+	}
+	return n / d, nil
+}
+
+func parseRawVisibilitySM(sm []string) (*Visibility, error) {
+	vis := &Visibility{
+		GreaterThan: sm[1] == "P",
+		LessThan:    sm[1] == "M",
+	}
+	if sm[2] != "" {
+		v, err := strconv.ParseFloat(sm[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		vis.Visibility = v
+		return vis, nil
+	}
+	v, err := parseFraction(sm[3], sm[4])
+	if err != nil {
+		return nil, err
+	}
+	vis.Visibility = v
+	return vis, nil
+}
+
+// metersToVisibility converts a metric visibility to statute miles as AWC
+// does.
+func metersToVisibility(meters float64) *Visibility {
+	if meters >= 9999 {
+		return &Visibility{Visibility: 6, GreaterThan: true}
+	}
+	return &Visibility{Visibility: math.Round(meters/metersPerStatuteMile*100) / 100}
+}
+
+func parseRawRVR(sm []string) RunwayVisualRange {
+	rvr := RunwayVisualRange{
+		Runway:      sm[1],
+		LessThan:    sm[2] == "M",
+		GreaterThan: sm[2] == "P" || sm[4] == "P",
+		Trend:       sm[7],
+	}
+
+	factor := feetPerMeter
+	if sm[6] == "FT" {
+		factor = 1
+	}
+
+	v, _ := strconv.ParseFloat(sm[3], 64)
+	rvr.Visibility = math.Round(v * factor)
+
+	if sm[5] != "" {
+		mx, _ := strconv.ParseFloat(sm[5], 64)
+		rvr.VariableTo = math.Round(mx * factor)
+	}
+
+	return rvr
+}
+
+// parseRawSkyLayer decodes a sky condition group, with vertical visibility as
+// an obscured layer as AWC reports it.
+func parseRawSkyLayer(m *METAR, sm []string) CloudLayer {
+	var base *float64
+	if sm[2] != "///" {
+		hundreds, _ := strconv.Atoi(sm[2])
+		b := float64(hundreds * 100)
+		base = &b
+	}
+	if sm[1] == "VV" {
+		m.VerticalVisibility = base
+		zero := 0.0
+		return CloudLayer{Cover: CloudCoverObscured, Base: &zero}
+	}
+	return CloudLayer{Cover: CloudCover(sm[1]), Base: base}
+}
+
+func parseRawTemperature(s string) float64 {
+	neg := strings.HasPrefix(s, "M")
+	v, _ := strconv.ParseFloat(strings.TrimPrefix(s, "M"), 64)
+	if neg {
+		return -v
+	}
+	return v
+}
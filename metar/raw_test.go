@@ -0,0 +1,281 @@
+package metar_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+)
+
+func TestParseRaw(t *testing.T) {
+	type fixture struct {
+		name string
+		raw  string
+		exp  metar.METAR
+		cat  metar.FlightCategory
+	}
+
+	ref := time.Date(2024, 4, 14, 18, 0, 0, 0, time.UTC)
+
+	fixtures := []fixture{
+		{
+			name: "auto gusts",
+			raw:  "KFIT 141352Z AUTO 26010G15KT 10SM CLR 12/M01 A2985 RMK AO2 SLP109 T01171006",
+			cat:  metar.FlightCategoryVFR,
+			exp: metar.METAR{
+				ICAOID:          "KFIT",
+				ObservationTime: metar.Time(time.Date(2024, 4, 14, 13, 52, 0, 0, time.UTC)),
+				Auto:            true,
+				WindDirection:   metar.WindDirection{From: 260},
+				WindSpeed:       10,
+				WindGust:        15,
+				Visibility:      &metar.Visibility{Visibility: 10},
+				Temperature:     12,
+				Dewpoint:        -1,
+				HasTemperature:  true,
+				HasDewpoint:     true,
+				Altimeter:       1010.8,
+				Clouds:          []metar.CloudLayer{{Cover: metar.CloudCoverClear}},
+			},
+		},
+		{
+			name: "speci fractional visibility and vertical visibility",
+			raw:  "SPECI KRNM 141448Z AUTO 00000KT 1 1/2SM R16/1200V2400FT/U -RA BR VV002 07/07 A3015 RMK AO2",
+			cat:  metar.FlightCategoryLIFR,
+			exp: metar.METAR{
+				ICAOID:          "KRNM",
+				MetarType:       metar.METARTypeSpecial,
+				ObservationTime: metar.Time(time.Date(2024, 4, 14, 14, 48, 0, 0, time.UTC)),
+				Auto:            true,
+				Visibility:      &metar.Visibility{Visibility: 1.5},
+				RunwayVisualRanges: []metar.RunwayVisualRange{
+					{Runway: "16", Visibility: 1200, VariableTo: 2400, Trend: "U"},
+				},
+				WxString:           "-RA BR",
+				VerticalVisibility: floatPtr(200),
+				Clouds:             []metar.CloudLayer{{Cover: metar.CloudCoverObscured, Base: floatPtr(0)}},
+				Temperature:        7,
+				Dewpoint:           7,
+				HasTemperature:     true,
+				HasDewpoint:        true,
+				Altimeter:          1021.0,
+			},
+		},
+		{
+			name: "less than quarter mile",
+			raw:  "METAR COR PASM 141522Z 26004KT M1/4SM FZFG OVC003 M03/M03 A3024",
+			cat:  metar.FlightCategoryLIFR,
+			exp: metar.METAR{
+				ICAOID:          "PASM",
+				ObservationTime: metar.Time(time.Date(2024, 4, 14, 15, 22, 0, 0, time.UTC)),
+				Corrected:       true,
+				WindDirection:   metar.WindDirection{From: 260},
+				WindSpeed:       4,
+				Visibility:      &metar.Visibility{Visibility: 0.25, LessThan: true},
+				WxString:        "FZFG",
+				Clouds:          []metar.CloudLayer{{Cover: metar.CloudCoverOvercast, Base: floatPtr(300)}},
+				Temperature:     -3,
+				Dewpoint:        -3,
+				HasTemperature:  true,
+				HasDewpoint:     true,
+				Altimeter:       1024,
+			},
+		},
+		{
+			name: "metric with variable sector",
+			raw:  "UWSG 141600Z 02003MPS 290V100 4900 -SHRA BKN015CB OVC020 12/06 Q1011 R08/CLRD70 NOSIG RMK QFE756/1008",
+			cat:  metar.FlightCategoryMVFR,
+			exp: metar.METAR{
+				ICAOID:          "UWSG",
+				ObservationTime: metar.Time(time.Date(2024, 4, 14, 16, 0, 0, 0, time.UTC)),
+				WindDirection:   metar.WindDirection{From: 20},
+				WindSpeed:       6,
+				WindVariability: &metar.WindVariability{From: 290, To: 100},
+				Visibility:      &metar.Visibility{Visibility: 3.04},
+				WxString:        "-SHRA",
+				Clouds: []metar.CloudLayer{
+					{Cover: metar.CloudCoverBroken, Base: floatPtr(1500)},
+					{Cover: metar.CloudCoverOvercast, Base: floatPtr(2000)},
+				},
+				Temperature:    12,
+				Dewpoint:       6,
+				HasTemperature: true,
+				HasDewpoint:    true,
+				Altimeter:      1011,
+			},
+		},
+		{
+			name: "cavok previous month",
+			raw:  "ZJHK 301600Z VRB02KT CAVOK 26/24 Q1010 NOSIG",
+			cat:  metar.FlightCategoryVFR,
+			exp: metar.METAR{
+				ICAOID:          "ZJHK",
+				ObservationTime: metar.Time(time.Date(2024, 3, 30, 16, 0, 0, 0, time.UTC)),
+				WindDirection:   metar.WindDirection{Variable: true},
+				WindSpeed:       2,
+				Visibility:      &metar.Visibility{Visibility: 6, GreaterThan: true},
+				Clouds:          []metar.CloudLayer{{Cover: metar.CloudCoverCAVOK}},
+				Temperature:     26,
+				Dewpoint:        24,
+				HasTemperature:  true,
+				HasDewpoint:     true,
+				Altimeter:       1010,
+			},
+		},
+		{
+			name: "bare light intensity",
+			raw:  "KFIT 141352Z 26010KT 10SM - CLR 12/M01 A2985",
+			cat:  metar.FlightCategoryVFR,
+			exp: metar.METAR{
+				ICAOID:          "KFIT",
+				ObservationTime: metar.Time(time.Date(2024, 4, 14, 13, 52, 0, 0, time.UTC)),
+				WindDirection:   metar.WindDirection{From: 260},
+				WindSpeed:       10,
+				Visibility:      &metar.Visibility{Visibility: 10},
+				Clouds:          []metar.CloudLayer{{Cover: metar.CloudCoverClear}},
+				Temperature:     12,
+				HasTemperature:  true,
+				Dewpoint:        -1,
+				HasDewpoint:     true,
+				Altimeter:       1010.8,
+			},
+		},
+		{
+			name: "bare heavy intensity",
+			raw:  "KFIT 141352Z 26010KT 10SM + CLR 12/M01 A2985",
+			cat:  metar.FlightCategoryVFR,
+			exp: metar.METAR{
+				ICAOID:          "KFIT",
+				ObservationTime: metar.Time(time.Date(2024, 4, 14, 13, 52, 0, 0, time.UTC)),
+				WindDirection:   metar.WindDirection{From: 260},
+				WindSpeed:       10,
+				Visibility:      &metar.Visibility{Visibility: 10},
+				Clouds:          []metar.CloudLayer{{Cover: metar.CloudCoverClear}},
+				Temperature:     12,
+				HasTemperature:  true,
+				Dewpoint:        -1,
+				HasDewpoint:     true,
+				Altimeter:       1010.8,
+			},
+		},
+		{
+			name: "bare vicinity",
+			raw:  "KFIT 141352Z 26010KT 10SM VC CLR 12/M01 A2985",
+			cat:  metar.FlightCategoryVFR,
+			exp: metar.METAR{
+				ICAOID:          "KFIT",
+				ObservationTime: metar.Time(time.Date(2024, 4, 14, 13, 52, 0, 0, time.UTC)),
+				WindDirection:   metar.WindDirection{From: 260},
+				WindSpeed:       10,
+				Visibility:      &metar.Visibility{Visibility: 10},
+				Clouds:          []metar.CloudLayer{{Cover: metar.CloudCoverClear}},
+				Temperature:     12,
+				HasTemperature:  true,
+				Dewpoint:        -1,
+				HasDewpoint:     true,
+				Altimeter:       1010.8,
+			},
+		},
+		{
+			name: "missing dewpoint",
+			raw:  "KFIT 141352Z 26010KT 10SM CLR M05/ A2985",
+			cat:  metar.FlightCategoryVFR,
+			exp: metar.METAR{
+				ICAOID:          "KFIT",
+				ObservationTime: metar.Time(time.Date(2024, 4, 14, 13, 52, 0, 0, time.UTC)),
+				WindDirection:   metar.WindDirection{From: 260},
+				WindSpeed:       10,
+				Visibility:      &metar.Visibility{Visibility: 10},
+				Clouds:          []metar.CloudLayer{{Cover: metar.CloudCoverClear}},
+				Temperature:     -5,
+				HasTemperature:  true,
+				Altimeter:       1010.8,
+			},
+		},
+		{
+			name: "missing temperature",
+			raw:  "KFIT 141352Z 26010KT 10SM CLR A2985",
+			cat:  metar.FlightCategoryVFR,
+			exp: metar.METAR{
+				ICAOID:          "KFIT",
+				ObservationTime: metar.Time(time.Date(2024, 4, 14, 13, 52, 0, 0, time.UTC)),
+				WindDirection:   metar.WindDirection{From: 260},
+				WindSpeed:       10,
+				Visibility:      &metar.Visibility{Visibility: 10},
+				Clouds:          []metar.CloudLayer{{Cover: metar.CloudCoverClear}},
+				Altimeter:       1010.8,
+			},
+		},
+	}
+
+	for _, f := range fixtures {
+		t.Run(f.name, func(t *testing.T) {
+			m, err := metar.ParseRawAt(f.raw, ref)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			exp := f.exp
+			exp.RawObservation = f.raw
+			if exp.MetarType == "" {
+				exp.MetarType = metar.METARTypeMETAR
+			}
+
+			got, _ := json.Marshal(m)
+			want, _ := json.Marshal(exp)
+			if string(got) != string(want) {
+				t.Fatalf("expected %s, got %s", want, got)
+			}
+
+			if cat := m.FlightCategory(); cat != f.cat {
+				t.Fatalf("expected %v, got %v", f.cat, cat)
+			}
+		})
+	}
+}
+
+func TestParseRawErrors(t *testing.T) {
+	fixtures := []string{
+		"",
+		"KBOS",
+		"KBOS 14135Z 26010KT 10SM CLR 12/M01 A2985",
+		"KBOS 141352Z NIL",
+	}
+
+	for _, f := range fixtures {
+		if _, err := metar.ParseRaw(f); err == nil {
+			t.Fatalf("expected error for %q", f)
+		}
+	}
+}
+
+func TestParseRawMatchesFlightCategory(t *testing.T) {
+
+	bts, err := os.ReadFile("testdata/2024-04-14.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var all []metar.METAR
+
+	if err := json.Unmarshal(bts, &all); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ref := time.Date(2024, 4, 14, 18, 0, 0, 0, time.UTC)
+
+	for _, exp := range all {
+		m, err := metar.ParseRawAt(exp.RawObservation, ref)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", exp.RawObservation, err)
+		}
+		if m.ICAOID != exp.ICAOID {
+			t.Fatalf("%s: expected %s, got %s", exp.RawObservation, exp.ICAOID, m.ICAOID)
+		}
+		if got, want := m.FlightCategory(), exp.FlightCategory(); got != want {
+			t.Errorf("%s: expected %v, got %v", exp.RawObservation, want, got)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package metar
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// visibilityTolerance is how close, relative to a limit, a visibility is
+// treated as at the limit. Visibilities reported in meters convert to just
+// under the statute miles they stand for, e.g. 1600 m is 0.99 SM.
+const visibilityTolerance = 0.02
+
+// Limit is an upper bound on a ceiling or visibility. The zero Limit contains
+// nothing.
+type Limit struct {
+	Value float64
+	// Inclusive includes Value itself within the limit.
+	Inclusive bool
+}
+
+func (l Limit) contains(v float64) bool {
+	if l.Inclusive {
+		return v <= l.Value
+	}
+	return v < l.Value
+}
+
+func (l Limit) containsVisibility(v Visibility) bool {
+	switch {
+	case v.LessThan:
+		return v.Visibility <= l.Value
+	case v.GreaterThan:
+		return v.Visibility < l.Value
+	}
+	if math.Abs(v.Visibility-l.Value) <= l.Value*visibilityTolerance {
+		return l.Inclusive
+	}
+	return l.contains(v.Visibility)
+}
+
+func (l Limit) String() string {
+	op := "<"
+	if l.Inclusive {
+		op = "<="
+	}
+	return op + strconv.FormatFloat(l.Value, 'f', -1, 64)
+}
+
+// Threshold is the ceiling, in feet, and visibility, in statute miles,
+// limits of a flight category.
+type Threshold struct {
+	Category   FlightCategory
+	Ceiling    Limit
+	Visibility Limit
+}
+
+// String returns the threshold in the format read by ParseThreshold.
+func (t Threshold) String() string {
+	out := t.Category.Abbreviation()
+	if t.Ceiling != (Limit{}) {
+		out += ":ceiling" + t.Ceiling.String()
+	}
+	if t.Visibility != (Limit{}) {
+		out += ":visibility" + t.Visibility.String()
+	}
+	return out
+}
+
+// ParseThreshold parses a threshold such as "IFR:ceiling<1000:visibility<3".
+// Limits are "<" or "<=" a value, and either may be left out. Visibility is in
+// statute miles unless suffixed with "m" or "km".
+func ParseThreshold(s string) (Threshold, error) {
+
+	parts := strings.Split(strings.TrimSpace(s), ":")
+
+	cat, ok := ParseFlightCategory(parts[0])
+	if !ok || cat == FlightCategoryVFR || cat == FlightCategoryUnknown {
+		return Threshold{}, fmt.Errorf("invalid threshold category: %s", parts[0])
+	}
+
+	t := Threshold{Category: cat}
+
+	for _, p := range parts[1:] {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch {
+		case strings.HasPrefix(p, "ceiling"):
+			l, err := parseLimit(strings.TrimPrefix(p, "ceiling"), nil)
+			if err != nil {
+				return Threshold{}, fmt.Errorf("invalid ceiling limit %q: %w", p, err)
+			}
+			t.Ceiling = l
+		case strings.HasPrefix(p, "visibility"):
+			l, err := parseLimit(strings.TrimPrefix(p, "visibility"), map[string]float64{
+				"km": 1000 / metersPerStatuteMile,
+				"m":  1 / metersPerStatuteMile,
+				"sm": 1,
+			})
+			if err != nil {
+				return Threshold{}, fmt.Errorf("invalid visibility limit %q: %w", p, err)
+			}
+			t.Visibility = l
+		default:
+			return Threshold{}, fmt.Errorf("invalid limit: %s", p)
+		}
+	}
+
+	return t, nil
+}
+
+// parseLimit parses "<value" or "<=value" with an optional unit suffix.
+func parseLimit(s string, units map[string]float64) (Limit, error) {
+
+	var l Limit
+
+	switch {
+	case strings.HasPrefix(s, "<="):
+		l.Inclusive = true
+		s = strings.TrimPrefix(s, "<=")
+	case strings.HasPrefix(s, "<"):
+		s = strings.TrimPrefix(s, "<")
+	default:
+		return Limit{}, fmt.Errorf("expected < or <=")
+	}
+
+	factor := 1.0
+	// Longer suffixes are checked first so "km" is not read as "m".
+	for _, u := range []string{"km", "sm", "m"} {
+		if f, ok := units[u]; ok && strings.HasSuffix(s, u) {
+			factor = f
+			s = strings.TrimSuffix(s, u)
+			break
+		}
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return Limit{}, err
+	}
+	l.Value = v * factor
+
+	return l, nil
+}
+
+// Rules determine flight categories from ceiling and visibility.
+type Rules struct {
+	// Thresholds from the worst category to the best. The first threshold
+	// whose limits contain the conditions gives the category, and conditions
+	// within none are VFR. Empty uses FAARules.
+	Thresholds []Threshold
+}
+
+// FAARules are the FAA flight category definitions.
+var FAARules = Rules{
+	Thresholds: []Threshold{
+		{Category: FlightCategoryLIFR, Ceiling: Limit{Value: 500}, Visibility: Limit{Value: 1}},
+		{Category: FlightCategoryIFR, Ceiling: Limit{Value: 1000}, Visibility: Limit{Value: 3}},
+		{Category: FlightCategoryMVFR, Ceiling: Limit{Value: 3000, Inclusive: true}, Visibility: Limit{Value: 5, Inclusive: true}},
+	},
+}
+
+// Validate checks that the thresholds are ordered from the worst category to
+// the best.
+func (r Rules) Validate() error {
+	prev := FlightCategoryUnknown
+	for _, t := range r.Thresholds {
+		if t.Category == FlightCategoryUnknown || t.Category == FlightCategoryVFR {
+			return fmt.Errorf("invalid threshold category: %s", t.Category)
+		}
+		if !prev.IsWorseThan(t.Category) {
+			return fmt.Errorf("threshold %s must be after %s", prev.Abbreviation(), t.Category.Abbreviation())
+		}
+		prev = t.Category
+	}
+	return nil
+}
+
+func (r *Rules) thresholds() []Threshold {
+	if r == nil || len(r.Thresholds) == 0 {
+		return FAARules.Thresholds
+	}
+	return r.Thresholds
+}
+
+// FlightCategory returns the flight category of a METAR. A nil Rules uses
+// FAARules.
+func (r *Rules) FlightCategory(m METAR) FlightCategory {
+	return r.categorize(m.Visibility, m.Clouds, m.VerticalVisibility)
+}
+
+// ForecastFlightCategory returns the flight category of a TAF change group.
+// A nil Rules uses FAARules.
+func (r *Rules) ForecastFlightCategory(f Forecast) FlightCategory {
+	return r.categorize(f.Visibility, f.Clouds, f.VerticalVisibility)
+}
+
+// TAFFlightCategoryAt returns the forecast flight category of a TAF at t, as
+// TAF.FlightCategoryAt does. A nil Rules uses FAARules.
+func (r *Rules) TAFFlightCategoryAt(taf TAF, t time.Time) FlightCategory {
+	return taf.flightCategoryAt(r, t)
+}
+
+func (r *Rules) categorize(vis *Visibility, clouds []CloudLayer, vv *float64) FlightCategory {
+
+	// CAVOK implies visibility of 10 km or more.
+	if vis == nil && hasCover(clouds, CloudCoverCAVOK) {
+		vis = &Visibility{Visibility: 6, GreaterThan: true}
+	}
+	if vis == nil {
+		return FlightCategoryUnknown
+	}
+
+	ceil, ok, known := ceiling(clouds, vv)
+	if !known {
+		return FlightCategoryUnknown
+	}
+
+	for _, t := range r.thresholds() {
+		if ok && t.Ceiling.contains(ceil) {
+			return t.Category
+		}
+		if t.Visibility.containsVisibility(*vis) {
+			return t.Category
+		}
+	}
+
+	return FlightCategoryVFR
+}
+
+// ceiling returns the height of the lowest broken, overcast or obscured
+// layer or vertical visibility, whether there is one, and whether it has a
+// height.
+func ceiling(clouds []CloudLayer, vv *float64) (float64, bool, bool) {
+
+	var (
+		out   float64
+		found bool
+	)
+
+	lower := func(h float64) {
+		if !found || h < out {
+			out = h
+			found = true
+		}
+	}
+
+	obscured := false
+	for _, lyr := range clouds {
+		if !lyr.Cover.IsCeiling() {
+			continue
+		}
+		if lyr.Cover == CloudCoverObscured {
+			obscured = true
+			if vv != nil {
+				lower(*vv)
+				continue
+			}
+		}
+		if lyr.Base == nil {
+			return 0, false, false
+		}
+		lower(*lyr.Base)
+	}
+
+	if !obscured && vv != nil {
+		lower(*vv)
+	}
+
+	return out, found, true
+}
+
+func hasCover(clouds []CloudLayer, cover CloudCover) bool {
+	for _, lyr := range clouds {
+		if lyr.Cover == cover {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package metar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+)
+
+func TestFAARules(t *testing.T) {
+	type fixture struct {
+		name string
+		raw  string
+		exp  metar.FlightCategory
+	}
+
+	fixtures := []fixture{
+		{name: "ceiling 3100 is VFR", raw: "KFIT 141352Z 26010KT 10SM OVC031 12/M01 A2985", exp: metar.FlightCategoryVFR},
+		{name: "ceiling 3000 is MVFR", raw: "KFIT 141352Z 26010KT 10SM OVC030 12/M01 A2985", exp: metar.FlightCategoryMVFR},
+		{name: "ceiling 1000 is MVFR", raw: "KFIT 141352Z 26010KT 10SM BKN010 12/M01 A2985", exp: metar.FlightCategoryMVFR},
+		{name: "ceiling 900 is IFR", raw: "KFIT 141352Z 26010KT 10SM BKN009 12/M01 A2985", exp: metar.FlightCategoryIFR},
+		{name: "ceiling 500 is IFR", raw: "KFIT 141352Z 26010KT 10SM OVC005 12/M01 A2985", exp: metar.FlightCategoryIFR},
+		{name: "ceiling 400 is LIFR", raw: "KFIT 141352Z 26010KT 10SM OVC004 12/M01 A2985", exp: metar.FlightCategoryLIFR},
+		{name: "scattered is not a ceiling", raw: "KFIT 141352Z 26010KT 10SM SCT002 12/M01 A2985", exp: metar.FlightCategoryVFR},
+		{name: "visibility 5 is MVFR", raw: "KFIT 141352Z 26010KT 5SM CLR 12/M01 A2985", exp: metar.FlightCategoryMVFR},
+		{name: "visibility 3 is MVFR", raw: "KFIT 141352Z 26010KT 3SM CLR 12/M01 A2985", exp: metar.FlightCategoryMVFR},
+		{name: "visibility 2 1/2 is IFR", raw: "KFIT 141352Z 26010KT 2 1/2SM CLR 12/M01 A2985", exp: metar.FlightCategoryIFR},
+		{name: "visibility 1 is IFR", raw: "KFIT 141352Z 26010KT 1SM CLR 12/M01 A2985", exp: metar.FlightCategoryIFR},
+		{name: "visibility M1/4 is LIFR", raw: "KFIT 141352Z 26010KT M1/4SM CLR 12/M01 A2985", exp: metar.FlightCategoryLIFR},
+		{name: "vertical visibility is a ceiling", raw: "KFIT 141352Z 26010KT 1SM VV011 12/M01 A2985", exp: metar.FlightCategoryIFR},
+		{name: "low vertical visibility", raw: "KFIT 141352Z 26010KT 3SM VV004 12/M01 A2985", exp: metar.FlightCategoryLIFR},
+		{name: "CAVOK", raw: "EGLL 141350Z 26010KT CAVOK 12/M01 Q1013", exp: metar.FlightCategoryVFR},
+		{name: "NSC", raw: "EGLL 141350Z 26010KT 9999 NSC 12/M01 Q1013", exp: metar.FlightCategoryVFR},
+		{name: "metric 1600 m is IFR", raw: "EGLL 141350Z 26010KT 1600 BR NSC 12/11 Q1013", exp: metar.FlightCategoryIFR},
+		{name: "metric 1500 m is LIFR", raw: "EGLL 141350Z 26010KT 1500 BR NSC 12/11 Q1013", exp: metar.FlightCategoryLIFR},
+		{name: "metric 8000 m is MVFR", raw: "EGLL 141350Z 26010KT 8000 NSC 12/11 Q1013", exp: metar.FlightCategoryMVFR},
+	}
+
+	ref := time.Date(2024, 4, 14, 18, 0, 0, 0, time.UTC)
+
+	for _, fix := range fixtures {
+		t.Run(fix.name, func(t *testing.T) {
+			m, err := metar.ParseRawAt(fix.raw, ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.FlightCategory(); got != fix.exp {
+				t.Fatalf("expected %v, got %v", fix.exp, got)
+			}
+		})
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+
+	var rules metar.Rules
+	for _, s := range []string{
+		"LIFR:ceiling<600:visibility<1500m",
+		"IFR:ceiling<1500:visibility<5km",
+		"MVFR:ceiling<=5000",
+	} {
+		th, err := metar.ParseThreshold(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules.Thresholds = append(rules.Thresholds, th)
+	}
+
+	if err := rules.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	type fixture struct {
+		raw string
+		exp metar.FlightCategory
+	}
+
+	fixtures := []fixture{
+		{raw: "EGLL 141350Z 26010KT 9999 BKN050 12/M01 Q1013", exp: metar.FlightCategoryMVFR},
+		{raw: "EGLL 141350Z 26010KT 9999 BKN060 12/M01 Q1013", exp: metar.FlightCategoryVFR},
+		{raw: "EGLL 141350Z 26010KT 4000 BKN060 12/M01 Q1013", exp: metar.FlightCategoryIFR},
+		{raw: "EGLL 141350Z 26010KT 1400 BKN060 12/M01 Q1013", exp: metar.FlightCategoryLIFR},
+		{raw: "EGLL 141350Z 26010KT 9999 BKN005 12/M01 Q1013", exp: metar.FlightCategoryLIFR},
+		// Visibility has no MVFR limit.
+		{raw: "EGLL 141350Z 26010KT 6000 NSC 12/M01 Q1013", exp: metar.FlightCategoryVFR},
+	}
+
+	ref := time.Date(2024, 4, 14, 18, 0, 0, 0, time.UTC)
+
+	for _, fix := range fixtures {
+		t.Run(fix.raw, func(t *testing.T) {
+			m, err := metar.ParseRawAt(fix.raw, ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rules.FlightCategory(m); got != fix.exp {
+				t.Fatalf("expected %v, got %v", fix.exp, got)
+			}
+		})
+	}
+}
+
+func TestParseThreshold(t *testing.T) {
+
+	th, err := metar.ParseThreshold("MVFR:ceiling<=3000:visibility<=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp, got := metar.FAARules.Thresholds[2], th; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	if exp, got := "MVFR:ceiling<=3000:visibility<=5", th.String(); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	for _, s := range []string{
+		"VFR:ceiling<3000",
+		"XFR:ceiling<3000",
+		"IFR:ceiling>3000",
+		"IFR:altitude<3000",
+		"IFR:visibility<3nm",
+	} {
+		if _, err := metar.ParseThreshold(s); err == nil {
+			t.Fatalf("expected error for %q", s)
+		}
+	}
+
+	unordered := metar.Rules{Thresholds: []metar.Threshold{
+		metar.FAARules.Thresholds[1],
+		metar.FAARules.Thresholds[0],
+	}}
+	if err := unordered.Validate(); err == nil {
+		t.Fatalf("expected error for unordered thresholds")
+	}
+}
@@ -0,0 +1,143 @@
+package metar
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Runway is a runway of an airport.
+type Runway struct {
+	// Ident is the identifier of both ends, e.g. "14/32".
+	Ident string
+	// Heading is the true heading in degrees of the low numbered end. The
+	// reciprocal end has the same crosswind.
+	Heading float64
+}
+
+// Runways are the open runways of each airport by ICAO identifier.
+type Runways map[string][]Runway
+
+// LoadRunways reads runways from a file in the OurAirports runways.csv
+// format, or from a .json file holding an array of objects with the same
+// fields.
+func LoadRunways(pth string) (Runways, error) {
+
+	f, err := os.Open(pth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open runways: %w", err)
+	}
+	defer f.Close()
+
+	if strings.ToLower(filepath.Ext(pth)) == ".json" {
+		return DecodeRunwaysJSON(f)
+	}
+	return DecodeRunwaysCSV(f)
+}
+
+// DecodeRunwaysCSV decodes runways in the OurAirports runways.csv format.
+// Closed runways and runways without a heading, such as helipads, are
+// skipped.
+func DecodeRunwaysCSV(r io.Reader) (Runways, error) {
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	cols, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read runways CSV header: %w", err)
+	}
+
+	out := make(Runways)
+
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read runways CSV: %w", err)
+		}
+
+		fields := make(map[string]string, len(rec))
+		for i, v := range rec {
+			if i < len(cols) {
+				fields[cols[i]] = v
+			}
+		}
+
+		out.add(fields)
+	}
+}
+
+// DecodeRunwaysJSON decodes an array of runways with the fields of the
+// OurAirports runways.csv format. Values may be strings or numbers.
+func DecodeRunwaysJSON(r io.Reader) (Runways, error) {
+
+	var recs []map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&recs); err != nil {
+		return nil, fmt.Errorf("failed to decode runways JSON: %w", err)
+	}
+
+	out := make(Runways)
+
+	for _, rec := range recs {
+		fields := make(map[string]string, len(rec))
+		for k, v := range rec {
+			var s string
+			if err := json.Unmarshal(v, &s); err == nil {
+				fields[k] = s
+				continue
+			}
+			fields[k] = string(bytes.Trim(v, `"`))
+		}
+		out.add(fields)
+	}
+
+	return out, nil
+}
+
+func (rwys Runways) add(fields map[string]string) {
+
+	id := strings.ToUpper(strings.TrimSpace(fields["airport_ident"]))
+	if id == "" || fields["closed"] == "1" || fields["closed"] == "true" {
+		return
+	}
+
+	le, he := strings.TrimSpace(fields["le_ident"]), strings.TrimSpace(fields["he_ident"])
+
+	hdg, ok := runwayHeading(fields["le_heading_degT"], le)
+	if !ok {
+		hdg, ok = runwayHeading(fields["he_heading_degT"], he)
+	}
+	if !ok {
+		return
+	}
+
+	ident := le
+	if he != "" {
+		ident += "/" + he
+	}
+
+	rwys[id] = append(rwys[id], Runway{Ident: ident, Heading: hdg})
+}
+
+// runwayHeading returns the true heading, or else the heading of the runway
+// number.
+func runwayHeading(degT string, ident string) (float64, bool) {
+	if v, err := strconv.ParseFloat(strings.TrimSpace(degT), 64); err == nil {
+		return v, true
+	}
+	num := strings.TrimRight(ident, "LCRW")
+	n, err := strconv.Atoi(num)
+	if err != nil || n < 1 || n > 36 {
+		return 0, false
+	}
+	return float64(n * 10), true
+}
@@ -0,0 +1,122 @@
+package metar
+
+import (
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+const (
+	DefaultStaleAfter = 2 * time.Hour
+	DefaultStaleDim   = 0.25
+)
+
+var (
+	DefaultMissingColor = ws2811.RGB{
+		Red:   32,
+		Green: 32,
+		Blue:  32,
+	}
+)
+
+// Status is the display state of an airport.
+type Status struct {
+	FlightCategory FlightCategory
+	BelowMinimums  bool
+	Lightning      bool
+	HighWind       bool
+	// GustFactor is the difference between the gust and wind speeds.
+	GustFactor          float64
+	VariableWind        bool
+	HighDensityAltitude bool
+	// Value is the value displayed by a gradient mode.
+	Value    float64
+	HasValue bool
+	Stale    bool
+	Missing  bool
+}
+
+func (st Status) String() string {
+	switch {
+	case st.Missing:
+		return "Missing"
+	}
+	out := st.FlightCategory.Name()
+	if st.BelowMinimums {
+		out += " (Below Minimums)"
+	}
+	if st.HighDensityAltitude {
+		out += " (High Density Altitude)"
+	}
+	if st.Stale {
+		out += " (Stale)"
+	}
+	return out
+}
+
+// IsStale reports whether the observation is older than staleAfter at now.
+// METARs without an observation time are never stale.
+func (m METAR) IsStale(now time.Time, staleAfter time.Duration) bool {
+	obs := time.Time(m.ObservationTime)
+	if obs.IsZero() {
+		return false
+	}
+	return now.Sub(obs) > staleAfter
+}
+
+func (srv *ColorServer) staleAfter() time.Duration {
+	if srv.StaleAfter > 0 {
+		return srv.StaleAfter
+	}
+	return DefaultStaleAfter
+}
+
+func (srv *ColorServer) staleDim() float64 {
+	if srv.StaleDim > 0 {
+		return srv.StaleDim
+	}
+	return DefaultStaleDim
+}
+
+// StatusToRGB returns the color of each LED for statuses.
+func (srv *ColorServer) StatusToRGB(statuses map[int]Status) map[int]ws2811.RGB {
+	colors := srv.Colors
+	if colors == nil {
+		colors = DefaultColors
+	}
+
+	out := make(map[int]ws2811.RGB, len(statuses))
+
+	for i, st := range statuses {
+		if st.Missing {
+			if c := srv.MissingColor; c != nil {
+				out[i] = *c
+			} else {
+				out[i] = DefaultMissingColor
+			}
+			continue
+		}
+
+		c := colors[st.FlightCategory]
+		switch {
+		case srv.Mode.IsGradient() && st.HasValue:
+			c = srv.gradient().Color(st.Value)
+		case srv.Mode.IsGradient():
+			c = colors[FlightCategoryUnknown]
+		case st.BelowMinimums:
+			c = srv.minimumsColor()
+		}
+
+		if st.Stale {
+			if sc := srv.StaleColor; sc != nil {
+				c = *sc
+			} else {
+				c = c.Scale(srv.staleDim())
+			}
+		}
+
+		out[i] = c
+	}
+
+	return out
+}
@@ -0,0 +1,68 @@
+package metar_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+func TestColorServerStatuses(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	stale := ws2811.RGB{Red: 1, Green: 2, Blue: 3}
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"CYYL", "KBOK", "ZZZZ"},
+		LEDIndexByAirportID: map[string]int{
+			"CYYL": 0,
+			"KBOK": 1,
+			"ZZZZ": 2,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: time.Since(time.Date(2024, 4, 14, 16, 12, 30, 0, time.UTC)),
+		StaleColor: &stale,
+	}
+
+	sts, err := srv.GetMETARs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exp := map[int]metar.Status{
+		0: {FlightCategory: metar.FlightCategoryIFR},
+		1: {FlightCategory: metar.FlightCategoryLIFR, Stale: true},
+		2: {Missing: true},
+	}
+
+	for idx, st := range exp {
+		if sts[idx] != st {
+			t.Fatalf("expected %v at %d, got %v", st, idx, sts[idx])
+		}
+	}
+
+	colors := srv.StatusToRGB(sts)
+
+	expColors := map[int]ws2811.RGB{
+		0: metar.DefaultColors[metar.FlightCategoryIFR],
+		1: stale,
+		2: metar.DefaultMissingColor,
+	}
+
+	for idx, c := range expColors {
+		if colors[idx] != c {
+			t.Fatalf("expected %v at %d, got %v", c, idx, colors[idx])
+		}
+	}
+
+	srv.StaleColor = nil
+
+	if c, exp := srv.StatusToRGB(sts)[1], metar.DefaultColors[metar.FlightCategoryLIFR].Scale(metar.DefaultStaleDim); c != exp {
+		t.Fatalf("expected %v, got %v", exp, c)
+	}
+}
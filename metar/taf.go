@@ -0,0 +1,193 @@
+package metar
+
+import (
+	"time"
+)
+
+type ChangeIndicator string
+
+const (
+	ChangeIndicatorFrom        = "FM"
+	ChangeIndicatorTemporary   = "TEMPO"
+	ChangeIndicatorBecoming    = "BECMG"
+	ChangeIndicatorProbability = "PROB"
+)
+
+func (ci ChangeIndicator) String() string {
+	return string(ci)
+}
+
+// IsTemporary reports whether the change group only describes temporary or
+// probable fluctuations from the prevailing conditions.
+func (ci ChangeIndicator) IsTemporary() bool {
+	switch ci {
+	case ChangeIndicatorTemporary, ChangeIndicatorProbability:
+		return true
+	}
+	return false
+}
+
+type TAF struct {
+	ID            int64      `json:"tafId"`
+	ICAOID        string     `json:"icaoId"`
+	BulletinTime  Time       `json:"bulletinTime"`
+	IssueTime     Time       `json:"issueTime"`
+	ValidTimeFrom Time       `json:"validTimeFrom"`
+	ValidTimeTo   Time       `json:"validTimeTo"`
+	RawTAF        string     `json:"rawTAF"`
+	MostRecent    float64    `json:"mostRecent"`
+	Remarks       string     `json:"remarks"`
+	Latitude      float64    `json:"lat"`
+	Longitude     float64    `json:"lon"`
+	Elevation     float64    `json:"elev"`
+	Prior         float64    `json:"prior"`
+	Name          string     `json:"name"`
+	Forecasts     []Forecast `json:"fcsts"`
+}
+
+// Forecast is a single TAF change group. The first group of a TAF has no
+// change indicator and describes the initial prevailing conditions.
+type Forecast struct {
+	TimeGroup          int             `json:"timeGroup"`
+	TimeFrom           Time            `json:"timeFrom"`
+	TimeTo             Time            `json:"timeTo"`
+	TimeBecoming       Time            `json:"timeBec"`
+	Change             ChangeIndicator `json:"fcstChange"`
+	Probability        *int            `json:"probability"`
+	WindDirection      *WindDirection  `json:"wdir"`
+	WindSpeed          *float64        `json:"wspd"`
+	WindGust           *float64        `json:"wgst"`
+	Visibility         *Visibility     `json:"visib"`
+	Altimeter          *float64        `json:"altim"`
+	VerticalVisibility *float64        `json:"vertVis"`
+	WxString           string          `json:"wxString"`
+	NotDecoded         string          `json:"notDecoded"`
+	Clouds             []CloudLayer    `json:"clouds"`
+}
+
+// FlightCategory returns the flight category of the group by FAARules.
+func (f Forecast) FlightCategory() FlightCategory {
+	return FAARules.ForecastFlightCategory(f)
+}
+
+func (f Forecast) activeAt(t time.Time) bool {
+	from, to := time.Time(f.TimeFrom), time.Time(f.TimeTo)
+	if t.Before(from) {
+		return false
+	}
+	return to.IsZero() || t.Before(to)
+}
+
+// merge returns f with any elements it omits taken from prev.
+func (f Forecast) merge(prev Forecast) Forecast {
+	if f.WindDirection == nil {
+		f.WindDirection = prev.WindDirection
+	}
+	if f.WindSpeed == nil {
+		f.WindSpeed = prev.WindSpeed
+		f.WindGust = prev.WindGust
+	}
+	if f.Visibility == nil {
+		f.Visibility = prev.Visibility
+	}
+	if f.Altimeter == nil {
+		f.Altimeter = prev.Altimeter
+	}
+	if len(f.Clouds) == 0 {
+		f.Clouds = prev.Clouds
+		if f.VerticalVisibility == nil {
+			f.VerticalVisibility = prev.VerticalVisibility
+		}
+	}
+	if f.WxString == "" {
+		f.WxString = prev.WxString
+	}
+	return f
+}
+
+// IsValidAt reports whether t falls within the TAF's valid period.
+func (taf TAF) IsValidAt(t time.Time) bool {
+	from, to := time.Time(taf.ValidTimeFrom), time.Time(taf.ValidTimeTo)
+	return !t.Before(from) && t.Before(to)
+}
+
+// ForecastAt returns the prevailing conditions forecast at t, without TEMPO
+// and PROB groups. It returns false when t is outside the valid period.
+func (taf TAF) ForecastAt(t time.Time) (Forecast, bool) {
+	if !taf.IsValidAt(t) || len(taf.Forecasts) == 0 {
+		return Forecast{}, false
+	}
+
+	var out Forecast
+
+	for _, f := range taf.Forecasts {
+		switch f.Change {
+		case "", ChangeIndicatorFrom:
+			if t.Before(time.Time(f.TimeFrom)) {
+				continue
+			}
+			out = f.merge(out)
+		case ChangeIndicatorBecoming:
+			if t.Before(time.Time(f.becomingComplete())) {
+				continue
+			}
+			out = f.merge(out)
+		}
+	}
+
+	return out, true
+}
+
+func (f Forecast) becomingComplete() Time {
+	if !time.Time(f.TimeBecoming).IsZero() {
+		return f.TimeBecoming
+	}
+	return f.TimeFrom
+}
+
+// FlightCategoryAt returns the forecast flight category at t. Conditions
+// changing in a BECMG group, and TEMPO or PROB groups in effect at t, only
+// count when they are worse than the prevailing conditions.
+func (taf TAF) FlightCategoryAt(t time.Time) FlightCategory {
+	return taf.flightCategoryAt(&FAARules, t)
+}
+
+func (taf TAF) flightCategoryAt(r *Rules, t time.Time) FlightCategory {
+	prevailing, ok := taf.ForecastAt(t)
+	if !ok {
+		return FlightCategoryUnknown
+	}
+
+	out := r.ForecastFlightCategory(prevailing)
+
+	for _, f := range taf.Forecasts {
+		var c FlightCategory
+		switch {
+		case f.Change.IsTemporary() && f.activeAt(t):
+			c = r.ForecastFlightCategory(f.merge(prevailing))
+		case f.Change == ChangeIndicatorBecoming && !t.Before(time.Time(f.TimeFrom)) && t.Before(time.Time(f.becomingComplete())):
+			c = r.ForecastFlightCategory(f.merge(prevailing))
+		default:
+			continue
+		}
+		if c != FlightCategoryUnknown && c.IsWorseThan(out) {
+			out = c
+		}
+	}
+
+	return out
+}
+
+// ForecastFlightCategory returns the forecast flight category for an airport
+// at t. It returns false when there is no TAF for the airport valid at t.
+func ForecastFlightCategory(tafs map[string]TAF, airportID string, t time.Time) (FlightCategory, bool) {
+	return FAARules.forecastFlightCategory(tafs, airportID, t)
+}
+
+func (r *Rules) forecastFlightCategory(tafs map[string]TAF, airportID string, t time.Time) (FlightCategory, bool) {
+	taf, ok := tafs[airportID]
+	if !ok || !taf.IsValidAt(t) {
+		return FlightCategoryUnknown, false
+	}
+	return taf.flightCategoryAt(r, t), true
+}
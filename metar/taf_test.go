@@ -0,0 +1,149 @@
+package metar_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+)
+
+func readTAFs(t *testing.T) []metar.TAF {
+	t.Helper()
+
+	bts, err := os.ReadFile("testdata/taf-2024-04-14.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out []metar.TAF
+	if err := json.Unmarshal(bts, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return out
+}
+
+func TestTAFFlightCategoryAt(t *testing.T) {
+	type fixture struct {
+		name string
+		at   time.Time
+		exp  metar.FlightCategory
+	}
+
+	taf := readTAFs(t)[0]
+
+	fixtures := []fixture{
+		{
+			name: "before valid",
+			at:   time.Date(2024, 4, 14, 17, 0, 0, 0, time.UTC),
+			exp:  metar.FlightCategoryUnknown,
+		},
+		{
+			name: "initial",
+			at:   time.Date(2024, 4, 14, 18, 0, 0, 0, time.UTC),
+			exp:  metar.FlightCategoryVFR,
+		},
+		{
+			name: "from",
+			at:   time.Date(2024, 4, 14, 23, 0, 0, 0, time.UTC),
+			exp:  metar.FlightCategoryVFR,
+		},
+		{
+			name: "tempo",
+			at:   time.Date(2024, 4, 15, 2, 0, 0, 0, time.UTC),
+			exp:  metar.FlightCategoryMVFR,
+		},
+		{
+			name: "second from",
+			at:   time.Date(2024, 4, 15, 8, 0, 0, 0, time.UTC),
+			exp:  metar.FlightCategoryIFR,
+		},
+		{
+			name: "becoming",
+			at:   time.Date(2024, 4, 15, 13, 0, 0, 0, time.UTC),
+			exp:  metar.FlightCategoryIFR,
+		},
+		{
+			name: "became",
+			at:   time.Date(2024, 4, 15, 15, 0, 0, 0, time.UTC),
+			exp:  metar.FlightCategoryMVFR,
+		},
+		{
+			name: "prob",
+			at:   time.Date(2024, 4, 15, 19, 0, 0, 0, time.UTC),
+			exp:  metar.FlightCategoryLIFR,
+		},
+		{
+			name: "after valid",
+			at:   time.Date(2024, 4, 16, 0, 0, 0, 0, time.UTC),
+			exp:  metar.FlightCategoryUnknown,
+		},
+	}
+
+	for _, f := range fixtures {
+		t.Run(f.name, func(t *testing.T) {
+			if cat := taf.FlightCategoryAt(f.at); cat != f.exp {
+				t.Fatalf("expected %v, got %v", f.exp, cat)
+			}
+		})
+	}
+}
+
+func TestTAFForecastAtMergesBecoming(t *testing.T) {
+
+	taf := readTAFs(t)[0]
+
+	f, ok := taf.ForecastAt(time.Date(2024, 4, 15, 15, 0, 0, 0, time.UTC))
+	if !ok {
+		t.Fatal("expected forecast")
+	}
+
+	if f.WindDirection == nil || f.WindDirection.From != 200 {
+		t.Fatalf("expected wind from prior group, got %v", f.WindDirection)
+	}
+	if f.WxString != "BR" {
+		t.Fatalf("expected weather from prior group, got %q", f.WxString)
+	}
+}
+
+func TestGetTAFs(t *testing.T) {
+
+	bts, err := os.ReadFile("testdata/taf-2024-04-14.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/taf" {
+			http.NotFound(w, r)
+			return
+		}
+		if ids := r.URL.Query().Get("ids"); ids != "KBOS,KJFK" {
+			t.Errorf("unexpected ids: %s", ids)
+		}
+		w.Write(bts)
+	}))
+	defer srv.Close()
+
+	c := metar.Client{BaseURL: srv.URL}
+
+	tafs, err := c.GetTAFs(context.Background(), "KBOS", "KJFK")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	at := time.Date(2024, 4, 15, 8, 0, 0, 0, time.UTC)
+
+	if cat, ok := metar.ForecastFlightCategory(tafs, "KBOS", at); !ok || cat != metar.FlightCategoryIFR {
+		t.Fatalf("expected %v, got %v (%v)", metar.FlightCategoryIFR, cat, ok)
+	}
+
+	if _, ok := metar.ForecastFlightCategory(tafs, "KJFK", at); ok {
+		t.Fatal("expected no forecast for KJFK")
+	}
+}
@@ -0,0 +1,15 @@
+package metar
+
+import (
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+var (
+	// DefaultTemperatureGradient shows freezing temperatures in blue, 0°C in
+	// white and hot temperatures in red.
+	DefaultTemperatureGradient = Gradient{
+		{Value: -20, Color: ws2811.RGB{Red: 0, Green: 0, Blue: 255}},
+		{Value: 0, Color: ws2811.RGB{Red: 255, Green: 255, Blue: 255}},
+		{Value: 35, Color: ws2811.RGB{Red: 255, Green: 0, Blue: 0}},
+	}
+)
@@ -0,0 +1,122 @@
+package metar
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTGFTPURL = "https://tgftp.nws.noaa.gov/data/observations/metar/stations"
+)
+
+// TGFTPProvider gets METARs from the NOAA tgftp server, which has a raw text
+// file with the latest observation for each station.
+type TGFTPProvider struct {
+	// BaseURL is the directory of station files.
+	BaseURL string
+	// Client makes the requests. Its BaseURL and BatchSize are not used.
+	Client Client
+}
+
+func (p TGFTPProvider) stationURL(id string) (string, error) {
+	base := p.BaseURL
+	if base == "" {
+		base = DefaultTGFTPURL
+	}
+
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL: %w", err)
+	}
+	u.Path = path.Join(u.Path, id+".TXT")
+
+	return u.String(), nil
+}
+
+// GetMETARs gets the station file for each airport. Airports without a
+// station file are left out of the results.
+func (p TGFTPProvider) GetMETARs(ctx context.Context, airportIDs ...string) (map[string]METAR, error) {
+
+	if len(airportIDs) == 0 {
+		return nil, fmt.Errorf("no airport identifiers specified")
+	}
+
+	c := p.Client
+	c.BatchSize = 1
+
+	var mu sync.Mutex
+
+	out := make(map[string]METAR, len(airportIDs))
+
+	err := c.forEachBatch(ctx, airportIDs, func(ctx context.Context, batch []string) error {
+
+		u, err := p.stationURL(batch[0])
+		if err != nil {
+			return err
+		}
+
+		bts, err := c.fetch(ctx, u)
+		var se *StatusError
+		if errors.As(err, &se) && se.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		m, err := parseStationFile(string(bts))
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		out[m.ICAOID] = m
+
+		return nil
+	})
+	if err != nil {
+		return out, fmt.Errorf("failed retrieving METAR(s): %w", err)
+	}
+
+	return out, nil
+}
+
+// parseStationFile parses the update time and raw observation of a station
+// file.
+func parseStationFile(s string) (METAR, error) {
+
+	var (
+		ref = time.Now().UTC()
+		raw []string
+	)
+
+	sc := bufio.NewScanner(strings.NewReader(s))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if t, err := time.Parse("2006/01/02 15:04", line); err == nil {
+			ref = t
+			continue
+		}
+		raw = append(raw, line)
+	}
+
+	if len(raw) == 0 {
+		return METAR{}, fmt.Errorf("empty station file")
+	}
+
+	// Long reports are occasionally wrapped across lines.
+	return ParseRawAt(strings.Join(raw, " "), ref)
+}
@@ -0,0 +1,262 @@
+package metar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Intensity is the intensity or proximity of a weather phenomenon.
+type Intensity string
+
+const (
+	IntensityLight    Intensity = "-"
+	IntensityModerate Intensity = ""
+	IntensityHeavy    Intensity = "+"
+	IntensityVicinity Intensity = "VC"
+)
+
+// Descriptor qualifies a weather phenomenon.
+type Descriptor string
+
+const (
+	DescriptorShallow      Descriptor = "MI"
+	DescriptorPartial      Descriptor = "PR"
+	DescriptorPatches      Descriptor = "BC"
+	DescriptorLowDrifting  Descriptor = "DR"
+	DescriptorBlowing      Descriptor = "BL"
+	DescriptorShowers      Descriptor = "SH"
+	DescriptorThunderstorm Descriptor = "TS"
+	DescriptorFreezing     Descriptor = "FZ"
+)
+
+// Phenomenon is a type of precipitation, obscuration or other weather.
+type Phenomenon string
+
+const (
+	PhenomenonDrizzle       Phenomenon = "DZ"
+	PhenomenonRain          Phenomenon = "RA"
+	PhenomenonSnow          Phenomenon = "SN"
+	PhenomenonSnowGrains    Phenomenon = "SG"
+	PhenomenonIceCrystals   Phenomenon = "IC"
+	PhenomenonIcePellets    Phenomenon = "PL"
+	PhenomenonHail          Phenomenon = "GR"
+	PhenomenonSmallHail     Phenomenon = "GS"
+	PhenomenonUnknownPrecip Phenomenon = "UP"
+	PhenomenonMist          Phenomenon = "BR"
+	PhenomenonFog           Phenomenon = "FG"
+	PhenomenonSmoke         Phenomenon = "FU"
+	PhenomenonVolcanicAsh   Phenomenon = "VA"
+	PhenomenonDust          Phenomenon = "DU"
+	PhenomenonSand          Phenomenon = "SA"
+	PhenomenonHaze          Phenomenon = "HZ"
+	PhenomenonSpray         Phenomenon = "PY"
+	PhenomenonDustWhirls    Phenomenon = "PO"
+	PhenomenonSqualls       Phenomenon = "SQ"
+	PhenomenonFunnelCloud   Phenomenon = "FC"
+	PhenomenonSandstorm     Phenomenon = "SS"
+	PhenomenonDuststorm     Phenomenon = "DS"
+)
+
+var descriptors = map[Descriptor]bool{
+	DescriptorShallow:      true,
+	DescriptorPartial:      true,
+	DescriptorPatches:      true,
+	DescriptorLowDrifting:  true,
+	DescriptorBlowing:      true,
+	DescriptorShowers:      true,
+	DescriptorThunderstorm: true,
+	DescriptorFreezing:     true,
+}
+
+var phenomena = map[Phenomenon]bool{
+	PhenomenonDrizzle:       true,
+	PhenomenonRain:          true,
+	PhenomenonSnow:          true,
+	PhenomenonSnowGrains:    true,
+	PhenomenonIceCrystals:   true,
+	PhenomenonIcePellets:    true,
+	PhenomenonHail:          true,
+	PhenomenonSmallHail:     true,
+	PhenomenonUnknownPrecip: true,
+	PhenomenonMist:          true,
+	PhenomenonFog:           true,
+	PhenomenonSmoke:         true,
+	PhenomenonVolcanicAsh:   true,
+	PhenomenonDust:          true,
+	PhenomenonSand:          true,
+	PhenomenonHaze:          true,
+	PhenomenonSpray:         true,
+	PhenomenonDustWhirls:    true,
+	PhenomenonSqualls:       true,
+	PhenomenonFunnelCloud:   true,
+	PhenomenonSandstorm:     true,
+	PhenomenonDuststorm:     true,
+}
+
+// IsPrecipitation reports whether the phenomenon is a form of precipitation.
+func (p Phenomenon) IsPrecipitation() bool {
+	switch p {
+	case PhenomenonDrizzle, PhenomenonRain, PhenomenonSnow, PhenomenonSnowGrains, PhenomenonIceCrystals,
+		PhenomenonIcePellets, PhenomenonHail, PhenomenonSmallHail, PhenomenonUnknownPrecip:
+		return true
+	}
+	return false
+}
+
+// IsObscuration reports whether the phenomenon obscures visibility.
+func (p Phenomenon) IsObscuration() bool {
+	switch p {
+	case PhenomenonMist, PhenomenonFog, PhenomenonSmoke, PhenomenonVolcanicAsh, PhenomenonDust,
+		PhenomenonSand, PhenomenonHaze, PhenomenonSpray:
+		return true
+	}
+	return false
+}
+
+// Weather is a present weather group, e.g. "-FZRA" or "VCTS".
+type Weather struct {
+	Intensity  Intensity
+	Descriptor Descriptor
+	// Phenomena are empty for a thunderstorm without precipitation.
+	Phenomena []Phenomenon
+}
+
+func (w Weather) String() string {
+	var sb strings.Builder
+	sb.WriteString(string(w.Intensity))
+	sb.WriteString(string(w.Descriptor))
+	for _, p := range w.Phenomena {
+		sb.WriteString(string(p))
+	}
+	return sb.String()
+}
+
+// Has reports whether the group includes the phenomenon.
+func (w Weather) Has(p Phenomenon) bool {
+	for _, wp := range w.Phenomena {
+		if wp == p {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseWeather parses a present weather group such as "+TSRA", "VCSH" or
+// "FZFG".
+func ParseWeather(s string) (Weather, error) {
+
+	var w Weather
+
+	rest := s
+	switch {
+	case strings.HasPrefix(rest, "-"):
+		w.Intensity = IntensityLight
+	case strings.HasPrefix(rest, "+"):
+		w.Intensity = IntensityHeavy
+	case strings.HasPrefix(rest, "VC"):
+		w.Intensity = IntensityVicinity
+	}
+	rest = strings.TrimPrefix(rest, string(w.Intensity))
+
+	if len(rest) >= 2 && descriptors[Descriptor(rest[:2])] {
+		w.Descriptor = Descriptor(rest[:2])
+		rest = rest[2:]
+	}
+
+	for len(rest) >= 2 {
+		p := Phenomenon(rest[:2])
+		if !phenomena[p] {
+			return Weather{}, fmt.Errorf("invalid weather phenomenon %q in %q", p, s)
+		}
+		w.Phenomena = append(w.Phenomena, p)
+		rest = rest[2:]
+	}
+
+	if rest != "" || (w.Descriptor == "" && len(w.Phenomena) == 0) {
+		return Weather{}, fmt.Errorf("invalid weather: %q", s)
+	}
+
+	return w, nil
+}
+
+// PresentWeather is the weather groups of an observation.
+type PresentWeather []Weather
+
+// ParseWxString parses the space separated weather groups of a WxString.
+// Groups that fail to parse are skipped.
+func ParseWxString(s string) PresentWeather {
+	var out PresentWeather
+	for _, tok := range strings.Fields(s) {
+		w, err := ParseWeather(tok)
+		if err != nil {
+			continue
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+// HasThunderstorm reports whether there is a thunderstorm at or in the
+// vicinity of the station.
+func (pw PresentWeather) HasThunderstorm() bool {
+	for _, w := range pw {
+		if w.Descriptor == DescriptorThunderstorm {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFreezingPrecip reports whether there is freezing drizzle, rain or
+// unknown precipitation at the station.
+func (pw PresentWeather) HasFreezingPrecip() bool {
+	for _, w := range pw {
+		if w.Descriptor != DescriptorFreezing || w.Intensity == IntensityVicinity {
+			continue
+		}
+		for _, p := range w.Phenomena {
+			if p.IsPrecipitation() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasObscuration reports whether visibility is obscured at the station, e.g.
+// by fog, mist, haze or smoke.
+func (pw PresentWeather) HasObscuration() bool {
+	for _, w := range pw {
+		if w.Intensity == IntensityVicinity {
+			continue
+		}
+		for _, p := range w.Phenomena {
+			if p.IsObscuration() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Weather returns the decoded WxString.
+func (m METAR) Weather() PresentWeather {
+	return ParseWxString(m.WxString)
+}
+
+// HasThunderstorm reports whether the METAR reports a thunderstorm at or in
+// the vicinity of the station.
+func (m METAR) HasThunderstorm() bool {
+	return m.Weather().HasThunderstorm()
+}
+
+// HasFreezingPrecip reports whether the METAR reports freezing
+// precipitation.
+func (m METAR) HasFreezingPrecip() bool {
+	return m.Weather().HasFreezingPrecip()
+}
+
+// HasObscuration reports whether the METAR reports an obscuration.
+func (m METAR) HasObscuration() bool {
+	return m.Weather().HasObscuration()
+}
@@ -0,0 +1,82 @@
+package metar_test
+
+import (
+	"testing"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+)
+
+func TestParseWeather(t *testing.T) {
+	type fixture struct {
+		s   string
+		exp metar.Weather
+	}
+
+	fixtures := []fixture{
+		{s: "RA", exp: metar.Weather{Phenomena: []metar.Phenomenon{metar.PhenomenonRain}}},
+		{s: "-FZRA", exp: metar.Weather{Intensity: metar.IntensityLight, Descriptor: metar.DescriptorFreezing, Phenomena: []metar.Phenomenon{metar.PhenomenonRain}}},
+		{s: "+TSRAGR", exp: metar.Weather{Intensity: metar.IntensityHeavy, Descriptor: metar.DescriptorThunderstorm, Phenomena: []metar.Phenomenon{metar.PhenomenonRain, metar.PhenomenonHail}}},
+		{s: "VCTS", exp: metar.Weather{Intensity: metar.IntensityVicinity, Descriptor: metar.DescriptorThunderstorm}},
+		{s: "VCSH", exp: metar.Weather{Intensity: metar.IntensityVicinity, Descriptor: metar.DescriptorShowers}},
+		{s: "BLSN", exp: metar.Weather{Descriptor: metar.DescriptorBlowing, Phenomena: []metar.Phenomenon{metar.PhenomenonSnow}}},
+		{s: "-RASN", exp: metar.Weather{Intensity: metar.IntensityLight, Phenomena: []metar.Phenomenon{metar.PhenomenonRain, metar.PhenomenonSnow}}},
+		{s: "FZFG", exp: metar.Weather{Descriptor: metar.DescriptorFreezing, Phenomena: []metar.Phenomenon{metar.PhenomenonFog}}},
+	}
+
+	for _, fix := range fixtures {
+		t.Run(fix.s, func(t *testing.T) {
+			w, err := metar.ParseWeather(fix.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w.String() != fix.exp.String() || w.Intensity != fix.exp.Intensity || w.Descriptor != fix.exp.Descriptor {
+				t.Fatalf("expected %+v, got %+v", fix.exp, w)
+			}
+			if w.String() != fix.s {
+				t.Fatalf("expected %v, got %v", fix.s, w.String())
+			}
+		})
+	}
+
+	for _, s := range []string{"", "-", "XX", "RAX", "+TSXX"} {
+		if _, err := metar.ParseWeather(s); err == nil {
+			t.Fatalf("expected error for %q", s)
+		}
+	}
+}
+
+func TestPresentWeatherHelpers(t *testing.T) {
+	type fixture struct {
+		wx           string
+		thunderstorm bool
+		freezing     bool
+		obscuration  bool
+	}
+
+	fixtures := []fixture{
+		{wx: ""},
+		{wx: "-RA BR", obscuration: true},
+		{wx: "+TSRA", thunderstorm: true},
+		{wx: "VCTS", thunderstorm: true},
+		{wx: "-FZDZ FG", freezing: true, obscuration: true},
+		{wx: "FZFG", obscuration: true},
+		{wx: "VCFG"},
+		{wx: "-RASN DRSN"},
+		{wx: "HZ FU", obscuration: true},
+	}
+
+	for _, fix := range fixtures {
+		t.Run(fix.wx, func(t *testing.T) {
+			m := metar.METAR{WxString: fix.wx}
+			if got := m.HasThunderstorm(); got != fix.thunderstorm {
+				t.Fatalf("expected thunderstorm %v, got %v", fix.thunderstorm, got)
+			}
+			if got := m.HasFreezingPrecip(); got != fix.freezing {
+				t.Fatalf("expected freezing precip %v, got %v", fix.freezing, got)
+			}
+			if got := m.HasObscuration(); got != fix.obscuration {
+				t.Fatalf("expected obscuration %v, got %v", fix.obscuration, got)
+			}
+		})
+	}
+}
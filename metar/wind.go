@@ -0,0 +1,91 @@
+package metar
+
+import (
+	"math"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+// WindEffect is how airports with high wind are animated.
+type WindEffect string
+
+const (
+	// WindEffectBlink alternates between the full and dimmed color.
+	WindEffectBlink WindEffect = "blink"
+	// WindEffectPulse smoothly fades between the full and dimmed color.
+	WindEffectPulse WindEffect = "pulse"
+)
+
+const (
+	DefaultWindThreshold = 25
+	DefaultWindPeriod    = 2 * time.Second
+	DefaultWindDim       = 0.2
+	// minWindPeriod bounds how fast the strongest gusts animate.
+	minWindPeriod = 250 * time.Millisecond
+)
+
+// WindOptions configure the animation of airports with high wind.
+type WindOptions struct {
+	// Threshold is the wind or gust speed, in knots, at which an airport is
+	// animated. Zero uses DefaultWindThreshold.
+	Threshold float64
+	// Effect is how the airport is animated. Zero uses WindEffectBlink.
+	Effect WindEffect
+	// Period is the length of one blink or pulse without gusts. Zero uses
+	// DefaultWindPeriod.
+	Period time.Duration
+	// Dim is the brightness of the dimmed color relative to the full color.
+	// Zero uses DefaultWindDim.
+	Dim float64
+}
+
+func (o WindOptions) threshold() float64 {
+	if o.Threshold > 0 {
+		return o.Threshold
+	}
+	return DefaultWindThreshold
+}
+
+func (o WindOptions) dim() float64 {
+	if o.Dim > 0 {
+		return o.Dim
+	}
+	return DefaultWindDim
+}
+
+// period halves the animation period for every 10 knots of gust factor.
+func (o WindOptions) period(gustFactor float64) time.Duration {
+	p := o.Period
+	if p <= 0 {
+		p = DefaultWindPeriod
+	}
+	p = time.Duration(float64(p) / (1 + math.Max(gustFactor, 0)/10))
+	if p < minWindPeriod {
+		return minWindPeriod
+	}
+	return p
+}
+
+// IsHighWind reports whether the wind or gusts reach threshold knots.
+func (m METAR) IsHighWind(threshold float64) bool {
+	return m.MaxWind() >= threshold
+}
+
+// GustFactor returns the difference in knots between the gust and sustained
+// wind speeds, or zero without gusts.
+func (m METAR) GustFactor() float64 {
+	if m.WindGust <= m.WindSpeed {
+		return 0
+	}
+	return m.WindGust - m.WindSpeed
+}
+
+// effect blinks or pulses c with the period for the gust factor.
+func (o WindOptions) effect(c ws2811.RGB, gustFactor float64) ws2811.Effect {
+	p := o.period(gustFactor)
+	if o.Effect == WindEffectPulse {
+		return ws2811.Pulse{Color: c, Dim: o.dim(), Period: p}
+	}
+	return ws2811.Blink{Color: c, Off: c.Scale(o.dim()), Period: p}
+}
@@ -0,0 +1,188 @@
+package metar_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/metar"
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+	"github.com/robfig/cron/v3"
+)
+
+func TestHighWind(t *testing.T) {
+	type fixture struct {
+		raw  string
+		high bool
+		gust float64
+	}
+
+	fixtures := []fixture{
+		{raw: "KFIT 141352Z 26010KT 10SM BKN040 12/M01 A2985"},
+		{raw: "KFIT 141352Z 26025KT 10SM BKN040 12/M01 A2985", high: true},
+		{raw: "KFIT 141352Z 26015G28KT 10SM BKN040 12/M01 A2985", high: true, gust: 13},
+		{raw: "KFIT 141352Z VRB05G19KT 10SM BKN040 12/M01 A2985", gust: 14},
+		{raw: "KFIT 141352Z 00000KT 10SM BKN040 12/M01 A2985"},
+	}
+
+	ref := time.Date(2024, 4, 14, 18, 0, 0, 0, time.UTC)
+
+	for _, fix := range fixtures {
+		t.Run(fix.raw, func(t *testing.T) {
+			m, err := metar.ParseRawAt(fix.raw, ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.IsHighWind(metar.DefaultWindThreshold); got != fix.high {
+				t.Fatalf("expected %v, got %v", fix.high, got)
+			}
+			if got := m.GustFactor(); got != fix.gust {
+				t.Fatalf("expected %v, got %v", fix.gust, got)
+			}
+		})
+	}
+}
+
+func TestColorServerWind(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"KAVP", "CYLU", "PAOU"},
+		LEDIndexByAirportID: map[string]int{
+			"KAVP": 0,
+			"CYLU": 1,
+			"PAOU": 2,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: 100000 * time.Hour,
+		Wind: &metar.WindOptions{
+			Threshold: 18,
+			Period:    time.Second,
+			Dim:       0.5,
+		},
+	}
+
+	scd, err := cron.ParseStandard("0 0 1 1 *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leds := make(chan ws2811.State)
+
+	go srv.Serve(ctx, scd, leds)
+
+	state := <-leds
+	colors := state.Colors(time.Unix(0, 0))
+
+	type fixture struct {
+		name string
+		at   time.Duration
+		exp  map[int]ws2811.RGB
+	}
+
+	// KAVP (VRB05G19KT) has a gust factor of 14 and blinks with a period of
+	// 417ms, CYLU (10036G42KT) a gust factor of 6 and a period of 625ms. PAOU
+	// (10KT) is below the threshold.
+	fixtures := []fixture{
+		{name: "both on", at: 0, exp: map[int]ws2811.RGB{
+			0: colors[0],
+			1: colors[1],
+			2: colors[2],
+		}},
+		{name: "KAVP dimmed", at: 300 * time.Millisecond, exp: map[int]ws2811.RGB{
+			0: colors[0].Scale(0.5),
+			1: colors[1],
+			2: colors[2],
+		}},
+		{name: "both dimmed", at: 400 * time.Millisecond, exp: map[int]ws2811.RGB{
+			0: colors[0].Scale(0.5),
+			1: colors[1].Scale(0.5),
+			2: colors[2],
+		}},
+	}
+
+	for _, fix := range fixtures {
+		t.Run(fix.name, func(t *testing.T) {
+			got := state.Colors(time.Unix(0, 0).Add(fix.at))
+			for idx, exp := range fix.exp {
+				if got[idx] != exp {
+					t.Fatalf("expected %v at %d, got %v", exp, idx, got[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestColorServerWindMode(t *testing.T) {
+
+	api := newTestdataServer(t)
+
+	srv := &metar.ColorServer{
+		AirportIDs: []string{"KAVP", "CYLU", "PAOU", "KUKF"},
+		LEDIndexByAirportID: map[string]int{
+			"KAVP": 0,
+			"CYLU": 1,
+			"PAOU": 2,
+			"KUKF": 3,
+		},
+		Client: metar.Client{
+			BaseURL: api.URL,
+		},
+		StaleAfter: 100000 * time.Hour,
+		Mode:       metar.ModeWind,
+	}
+
+	sts, err := srv.GetMETARs(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expStatus := map[int]metar.Status{
+		// VRB05G19KT, with LTG DSNT in the remarks.
+		0: {FlightCategory: metar.FlightCategoryIFR, Lightning: true, GustFactor: 14, VariableWind: true, Value: 5, HasValue: true},
+		// 10036G42KT
+		1: {FlightCategory: metar.FlightCategoryIFR, GustFactor: 6, Value: 36, HasValue: true},
+		// 32010KT
+		2: {FlightCategory: metar.FlightCategoryVFR, Value: 10, HasValue: true},
+	}
+
+	for idx, st := range expStatus {
+		if sts[idx] != st {
+			t.Fatalf("expected %+v at %d, got %+v", st, idx, sts[idx])
+		}
+	}
+
+	g := metar.DefaultWindGradient
+	state := srv.StatusToState(sts)
+
+	surge, ok := state[0].(ws2811.Surge)
+	if !ok {
+		t.Fatalf("expected a gust surge at 0, got %T", state[0])
+	}
+	if exp, got := (ws2811.Pulse{Color: g.Color(5), Dim: 0.5, Period: metar.DefaultVariableWindPeriod}), surge.Base; exp != got {
+		t.Fatalf("expected variable wind pulse %v, got %v", exp, got)
+	}
+	if surge.Period >= metar.DefaultWindPeriod {
+		t.Fatalf("expected gusts to shorten the surge period, got %v", surge.Period)
+	}
+
+	surge, ok = state[1].(ws2811.Surge)
+	if !ok {
+		t.Fatalf("expected a gust surge at 1, got %T", state[1])
+	}
+	if exp, got := ws2811.Static(g.Color(36)), surge.Base; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	for idx, speed := range map[int]float64{2: 10, 3: 5} {
+		if exp, got := ws2811.Static(g.Color(speed)), state[idx]; exp != got {
+			t.Fatalf("expected %v at %d, got %v", exp, idx, got)
+		}
+	}
+}
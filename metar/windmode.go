@@ -0,0 +1,61 @@
+package metar
+
+import (
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+const (
+	// DefaultGustSurge is how long the brightness surge on gusting airports
+	// lasts in ModeWind.
+	DefaultGustSurge = 600 * time.Millisecond
+	// DefaultVariableWindPeriod is the period of the slow pulse on airports
+	// with variable wind direction in ModeWind.
+	DefaultVariableWindPeriod = 4 * time.Second
+
+	// gustSurgePeak is how far toward white a gust surge brightens.
+	gustSurgePeak   = 0.6
+	variableWindDim = 0.5
+)
+
+var (
+	// DefaultWindGradient runs from green when calm through yellow at 10 kt
+	// and red at 30 kt to magenta at 40 kt and above.
+	DefaultWindGradient = Gradient{
+		{Value: 0, Color: ws2811.RGB{Red: 0, Green: 255, Blue: 0}},
+		{Value: 10, Color: ws2811.RGB{Red: 255, Green: 255, Blue: 0}},
+		{Value: 20, Color: ws2811.RGB{Red: 255, Green: 128, Blue: 0}},
+		{Value: 30, Color: ws2811.RGB{Red: 255, Green: 0, Blue: 0}},
+		{Value: 40, Color: ws2811.RGB{Red: 255, Green: 0, Blue: 255}},
+	}
+)
+
+// HasVariableWind reports whether the wind direction is reported as variable,
+// e.g. VRB05KT. Calm wind is not variable.
+func (m METAR) HasVariableWind() bool {
+	return m.WindDirection.Variable && m.WindSpeed > 0
+}
+
+// windModeEffect pulses variable wind and surges gusts on eff.
+func (srv *ColorServer) windModeEffect(eff ws2811.Effect, c ws2811.RGB, st Status) ws2811.Effect {
+
+	if st.VariableWind && !(st.HighWind && srv.Wind != nil) {
+		eff = ws2811.Pulse{Color: c, Dim: variableWindDim, Period: DefaultVariableWindPeriod}
+	}
+
+	if st.GustFactor > 0 {
+		var opts WindOptions
+		if srv.Wind != nil {
+			opts = *srv.Wind
+		}
+		eff = ws2811.Surge{
+			Base:     eff,
+			Peak:     ws2811.Mix(c, ws2811.RGB{Red: 255, Green: 255, Blue: 255}, gustSurgePeak),
+			Period:   opts.period(st.GustFactor),
+			Duration: DefaultGustSurge,
+		}
+	}
+
+	return eff
+}
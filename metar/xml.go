@@ -0,0 +1,103 @@
+package metar
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type addsXMLElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr       `xml:",any,attr"`
+	Value    string           `xml:",chardata"`
+	Children []addsXMLElement `xml:",any"`
+}
+
+func (e addsXMLElement) attr(name string) string {
+	for _, a := range e.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// DecodeXML decodes METARs in the ADDS XML format. METAR elements that fail
+// to decode are skipped.
+func DecodeXML(r io.Reader) ([]METAR, error) {
+
+	dec := xml.NewDecoder(r)
+
+	var (
+		out  []METAR
+		errs []string
+	)
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read XML: %w", err)
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch se.Name.Local {
+		case "error":
+			var msg string
+			if err := dec.DecodeElement(&msg, &se); err != nil {
+				return nil, fmt.Errorf("failed to read XML: %w", err)
+			}
+			errs = append(errs, strings.TrimSpace(msg))
+		case "METAR":
+			var el addsXMLElement
+			if err := dec.DecodeElement(&el, &se); err != nil {
+				return nil, fmt.Errorf("failed to read XML: %w", err)
+			}
+			m, err := decodeXMLMETAR(el)
+			if err != nil || m.ICAOID == "" {
+				continue
+			}
+			out = append(out, m)
+		}
+	}
+
+	if len(errs) > 0 {
+		return out, errors.New(strings.Join(errs, "; "))
+	}
+
+	return out, nil
+}
+
+func decodeXMLMETAR(el addsXMLElement) (METAR, error) {
+
+	var m METAR
+
+	for _, c := range el.Children {
+		switch c.XMLName.Local {
+		case "sky_condition":
+			if err := addADDSSkyCondition(&m, c.attr("sky_cover"), c.attr("cloud_base_ft_agl")); err != nil {
+				return METAR{}, err
+			}
+		case "quality_control_flags":
+			for _, f := range c.Children {
+				if err := setADDSField(&m, f.XMLName.Local, f.Value); err != nil {
+					return METAR{}, err
+				}
+			}
+		default:
+			if err := setADDSField(&m, c.XMLName.Local, c.Value); err != nil {
+				return METAR{}, err
+			}
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,36 @@
+package ws2811
+
+import (
+	ws281x "github.com/rpi-ws281x/rpi-ws281x-go"
+)
+
+// Strip is an initialized string of LEDs that can be rendered to.
+// *ws281x.WS2811 satisfies Strip.
+type Strip interface {
+	Init() error
+	Leds(channel int) []uint32
+	Render() error
+	Wait() error
+	Fini()
+}
+
+// Driver opens a Strip with the given driver options.
+type Driver interface {
+	Open(opt *ws281x.Option) (Strip, error)
+}
+
+// DriverFunc adapts a function to a Driver.
+type DriverFunc func(opt *ws281x.Option) (Strip, error)
+
+func (f DriverFunc) Open(opt *ws281x.Option) (Strip, error) {
+	return f(opt)
+}
+
+// WS281x is the Driver for LEDs attached to a Raspberry Pi through rpi-ws281x.
+var WS281x Driver = DriverFunc(func(opt *ws281x.Option) (Strip, error) {
+	drv, err := ws281x.MakeWS2811(opt)
+	if err != nil {
+		return nil, err
+	}
+	return drv, nil
+})
@@ -0,0 +1,234 @@
+package ws2811
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultFrameRate = 30
+)
+
+// Effect is the color of an LED over time. Effects are evaluated by
+// Controller.Serve on every frame.
+type Effect interface {
+	// At returns the color of the LED at t.
+	At(t time.Time) RGB
+}
+
+// State is the effect of each LED by index, published by producers to
+// Controller.Serve. LEDs without an effect are off.
+type State map[int]Effect
+
+// StaticState returns a State that displays colors without animation.
+func StaticState(colors map[int]RGB) State {
+	out := make(State, len(colors))
+	for i, c := range colors {
+		out[i] = Static(c)
+	}
+	return out
+}
+
+// Colors returns the color of each LED at t.
+func (s State) Colors(t time.Time) map[int]RGB {
+	out := make(map[int]RGB, len(s))
+	for i, eff := range s {
+		if eff == nil {
+			continue
+		}
+		out[i] = eff.At(t)
+	}
+	return out
+}
+
+// Static is a constant color.
+type Static RGB
+
+func (s Static) At(t time.Time) RGB {
+	return RGB(s)
+}
+
+// phase returns how far t is through a cycle of period p, from 0 to 1.
+func phase(t time.Time, p time.Duration) float64 {
+	if p <= 0 {
+		return 0
+	}
+	return float64(t.UnixNano()%int64(p)) / float64(p)
+}
+
+// Blink alternates between Color for the first half of every Period and Off
+// for the second.
+type Blink struct {
+	Color  RGB
+	Off    RGB
+	Period time.Duration
+}
+
+func (b Blink) At(t time.Time) RGB {
+	if phase(t, b.Period) < 0.5 {
+		return b.Color
+	}
+	return b.Off
+}
+
+// Pulse fades smoothly from Color to Color scaled by Dim and back every
+// Period.
+type Pulse struct {
+	Color  RGB
+	Dim    float64
+	Period time.Duration
+}
+
+func (p Pulse) At(t time.Time) RGB {
+	f := p.Dim + (1-p.Dim)*(0.5+0.5*math.Cos(2*math.Pi*phase(t, p.Period)))
+	return p.Color.Scale(f)
+}
+
+// Surge shows Base, brightening toward Peak and back over Duration at the
+// start of every Period.
+type Surge struct {
+	Base     Effect
+	Peak     RGB
+	Period   time.Duration
+	Duration time.Duration
+}
+
+func (s Surge) At(t time.Time) RGB {
+	c := s.Base.At(t)
+	if s.Duration <= 0 {
+		return c
+	}
+	d := time.Duration(phase(t, s.Period) * float64(s.Period))
+	if d >= s.Duration {
+		return c
+	}
+	return Mix(c, s.Peak, 1-math.Abs(2*float64(d)/float64(s.Duration)-1))
+}
+
+// Fade changes from From to the color of To over Duration from Start,
+// interpolated with Mix.
+type Fade struct {
+	From     RGB
+	To       Effect
+	Start    time.Time
+	Duration time.Duration
+}
+
+func (f Fade) At(t time.Time) RGB {
+	to := f.To.At(t)
+	if f.Duration <= 0 {
+		return to
+	}
+	return Mix(f.From, to, float64(t.Sub(f.Start))/float64(f.Duration))
+}
+
+// Attention pulses Base between full and Dim brightness every Period for
+// Duration from Start, then shows Base.
+type Attention struct {
+	Base     Effect
+	Start    time.Time
+	Duration time.Duration
+	Dim      float64
+	Period   time.Duration
+}
+
+func (a Attention) At(t time.Time) RGB {
+	c := a.Base.At(t)
+	d := t.Sub(a.Start)
+	if d < 0 || d >= a.Duration {
+		return c
+	}
+	// Pulses start dimmed so the change stands out.
+	return Pulse{Color: c, Dim: a.Dim, Period: a.Period}.At(time.Unix(0, 0).Add(d + a.Period/2))
+}
+
+// Flash shows Base, interrupted by flashes of Color at random intervals. It
+// must be used by pointer and for a single LED.
+type Flash struct {
+	Base        Effect
+	Color       RGB
+	Duration    time.Duration
+	MinInterval time.Duration
+	MaxInterval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func (f *Flash) At(t time.Time) RGB {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.next.IsZero() || t.After(f.next.Add(f.Duration)) {
+		f.next = t.Add(f.interval())
+	}
+	if !t.Before(f.next) {
+		return f.Color
+	}
+	if f.Base == nil {
+		return Off
+	}
+	return f.Base.At(t)
+}
+
+func (f *Flash) interval() time.Duration {
+	if f.MaxInterval <= f.MinInterval {
+		return f.MinInterval
+	}
+	return f.MinInterval + rand.N(f.MaxInterval-f.MinInterval)
+}
+
+func (ctrl *Controller) frameRate() int {
+	if ctrl.FrameRate > 0 {
+		return ctrl.FrameRate
+	}
+	return DefaultFrameRate
+}
+
+// transition fades each LED of state that changed from displayed.
+func (ctrl *Controller) transition(now time.Time, displayed map[int]RGB, state State) State {
+	if ctrl.Transition <= 0 || displayed == nil {
+		return state
+	}
+
+	out := make(State, len(state))
+	for i, eff := range state {
+		out[i] = eff
+	}
+	for i := range displayed {
+		if out[i] == nil {
+			out[i] = Static(Off)
+		}
+	}
+
+	// Only static effects are compared, as evaluating an effect such as a
+	// Flash advances it.
+	for i, eff := range out {
+		from := displayed[i]
+		if c, ok := eff.(Static); ok && RGB(c) == from {
+			continue
+		}
+		out[i] = Fade{
+			From:     from,
+			To:       eff,
+			Start:    now,
+			Duration: ctrl.Transition,
+		}
+	}
+
+	return out
+}
+
+func equalColors(a map[int]RGB, b map[int]RGB) bool {
+	if a == nil || b == nil || len(a) != len(b) {
+		return false
+	}
+	for i, c := range a {
+		if oc, ok := b[i]; !ok || oc != c {
+			return false
+		}
+	}
+	return true
+}
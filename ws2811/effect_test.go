@@ -0,0 +1,105 @@
+package ws2811_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+)
+
+func TestEffects(t *testing.T) {
+	type fixture struct {
+		name string
+		eff  ws2811.Effect
+		at   time.Duration
+		exp  ws2811.RGB
+	}
+
+	red := ws2811.RGB{Red: 200}
+	blue := ws2811.RGB{Blue: 100}
+	epoch := time.Unix(0, 0)
+
+	fixtures := []fixture{
+		{name: "static", eff: ws2811.Static(red), at: time.Hour, exp: red},
+		{name: "blink on", eff: ws2811.Blink{Color: red, Off: blue, Period: time.Second}, at: 400 * time.Millisecond, exp: red},
+		{name: "blink off", eff: ws2811.Blink{Color: red, Off: blue, Period: time.Second}, at: 600 * time.Millisecond, exp: blue},
+		{name: "blink next period", eff: ws2811.Blink{Color: red, Off: blue, Period: time.Second}, at: 1100 * time.Millisecond, exp: red},
+		{name: "pulse full", eff: ws2811.Pulse{Color: red, Dim: 0.5, Period: time.Second}, at: 0, exp: red},
+		{name: "pulse dim", eff: ws2811.Pulse{Color: red, Dim: 0.5, Period: time.Second}, at: 500 * time.Millisecond, exp: ws2811.RGB{Red: 100}},
+		{name: "surge peak", eff: ws2811.Surge{Base: ws2811.Static(red), Peak: blue, Period: time.Second, Duration: 200 * time.Millisecond}, at: 1100 * time.Millisecond, exp: blue},
+		{name: "surge over", eff: ws2811.Surge{Base: ws2811.Static(red), Peak: blue, Period: time.Second, Duration: 200 * time.Millisecond}, at: 1500 * time.Millisecond, exp: red},
+		{name: "fade before", eff: ws2811.Fade{From: red, To: ws2811.Static(blue), Start: epoch.Add(time.Second), Duration: time.Second}, at: 0, exp: red},
+		{name: "fade halfway", eff: ws2811.Fade{From: red, To: ws2811.Static(blue), Start: epoch, Duration: time.Second}, at: 500 * time.Millisecond, exp: ws2811.RGB{Red: 96, Green: 43, Blue: 77}},
+		{name: "fade to blink", eff: ws2811.Fade{From: red, To: ws2811.Blink{Color: red, Off: blue, Period: 4 * time.Second}, Start: epoch, Duration: time.Second}, at: 3 * time.Second, exp: blue},
+		{name: "attention pulse", eff: ws2811.Attention{Base: ws2811.Static(red), Start: epoch, Duration: 2 * time.Second, Dim: 0.5, Period: time.Second}, at: 0, exp: ws2811.RGB{Red: 100}},
+		{name: "attention over", eff: ws2811.Attention{Base: ws2811.Static(red), Start: epoch, Duration: 2 * time.Second, Dim: 0.5, Period: time.Second}, at: 2500 * time.Millisecond, exp: red},
+		{name: "fade after", eff: ws2811.Fade{From: red, To: ws2811.Static(blue), Start: epoch, Duration: time.Second}, at: 2 * time.Second, exp: blue},
+	}
+
+	for _, fix := range fixtures {
+		t.Run(fix.name, func(t *testing.T) {
+			if got := fix.eff.At(epoch.Add(fix.at)); got != fix.exp {
+				t.Fatalf("expected %v, got %v", fix.exp, got)
+			}
+		})
+	}
+}
+
+func TestFlash(t *testing.T) {
+
+	red := ws2811.RGB{Red: 200}
+	white := ws2811.RGB{Red: 255, Green: 255, Blue: 255}
+
+	flash := &ws2811.Flash{
+		Base:        ws2811.Static(red),
+		Color:       white,
+		Duration:    100 * time.Millisecond,
+		MinInterval: time.Second,
+		MaxInterval: time.Second,
+	}
+
+	start := time.Unix(0, 0)
+
+	for _, fix := range []struct {
+		at  time.Duration
+		exp ws2811.RGB
+	}{
+		// The first evaluation schedules a flash a second later.
+		{at: 0, exp: red},
+		{at: 999 * time.Millisecond, exp: red},
+		{at: time.Second, exp: white},
+		{at: 1100 * time.Millisecond, exp: white},
+		{at: 1200 * time.Millisecond, exp: red},
+		{at: 2200 * time.Millisecond, exp: white},
+	} {
+		if got := flash.At(start.Add(fix.at)); got != fix.exp {
+			t.Fatalf("expected %v at %v, got %v", fix.exp, fix.at, got)
+		}
+	}
+}
+
+func TestMix(t *testing.T) {
+	type fixture struct {
+		a   ws2811.RGB
+		b   ws2811.RGB
+		x   float64
+		exp ws2811.RGB
+	}
+
+	white := ws2811.RGB{Red: 255, Green: 255, Blue: 255}
+
+	fixtures := []fixture{
+		{a: ws2811.Off, b: white, x: 0, exp: ws2811.Off},
+		{a: ws2811.Off, b: white, x: 1, exp: white},
+		// Perceptual middle gray is darker than the RGB average.
+		{a: ws2811.Off, b: white, x: 0.5, exp: ws2811.RGB{Red: 99, Green: 99, Blue: 99}},
+		{a: ws2811.RGB{Green: 255}, b: ws2811.RGB{Red: 255}, x: 0.5, exp: ws2811.RGB{Red: 208, Green: 168}},
+		{a: ws2811.RGB{Red: 12, Green: 34, Blue: 56}, b: white, x: 0.000001, exp: ws2811.RGB{Red: 12, Green: 34, Blue: 56}},
+	}
+
+	for _, fix := range fixtures {
+		if got := ws2811.Mix(fix.a, fix.b, fix.x); got != fix.exp {
+			t.Fatalf("expected %v, got %v", fix.exp, got)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package ws2811
+
+import (
+	"math"
+)
+
+// oklab is a color in the OKLab perceptual color space.
+type oklab struct {
+	L, A, B float64
+}
+
+func srgbToLinear(c int) float64 {
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) int {
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	c := int(math.Round(v * 255))
+	switch {
+	case c < 0:
+		return 0
+	case c > 255:
+		return 255
+	}
+	return c
+}
+
+func (rgb RGB) oklab() oklab {
+	r, g, b := srgbToLinear(rgb.Red), srgbToLinear(rgb.Green), srgbToLinear(rgb.Blue)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	return oklab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+func (c oklab) rgb() RGB {
+	l := c.L + 0.3963377774*c.A + 0.2158037573*c.B
+	m := c.L - 0.1055613458*c.A - 0.0638541728*c.B
+	s := c.L - 0.0894841775*c.A - 1.2914855480*c.B
+
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	return RGB{
+		Red:   linearToSRGB(4.0767416621*l - 3.3077115913*m + 0.2309699292*s),
+		Green: linearToSRGB(-1.2684380046*l + 2.6097574011*m - 0.3413193965*s),
+		Blue:  linearToSRGB(-0.0041960863*l - 0.7034186147*m + 1.7076147010*s),
+	}
+}
+
+// Mix returns the color x of the way from a to b, interpolated in OKLab.
+func Mix(a RGB, b RGB, x float64) RGB {
+	switch {
+	case x <= 0:
+		return a
+	case x >= 1:
+		return b
+	}
+	ca, cb := a.oklab(), b.oklab()
+	return oklab{
+		L: ca.L + x*(cb.L-ca.L),
+		A: ca.A + x*(cb.A-ca.A),
+		B: ca.B + x*(cb.B-ca.B),
+	}.rgb()
+}
@@ -0,0 +1,110 @@
+package ws2811
+
+import (
+	"errors"
+	"sync"
+
+	ws281x "github.com/rpi-ws281x/rpi-ws281x-go"
+)
+
+// Simulator is a Driver for in-memory strips that record every rendered frame.
+// It is safe for concurrent use.
+type Simulator struct {
+	// OnRender, if set, is called with a copy of each frame after it is recorded.
+	OnRender func(frame []RGB)
+
+	mu     sync.Mutex
+	frames [][]RGB
+}
+
+func (sim *Simulator) Open(opt *ws281x.Option) (Strip, error) {
+	if len(opt.Channels) == 0 {
+		return nil, errors.New("no channels configured")
+	}
+	return &simulatedStrip{
+		sim:   sim,
+		count: opt.Channels[0].LedCount,
+	}, nil
+}
+
+// Frames returns every frame rendered so far, oldest first.
+func (sim *Simulator) Frames() [][]RGB {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	out := make([][]RGB, len(sim.frames))
+	for i, f := range sim.frames {
+		out[i] = append([]RGB(nil), f...)
+	}
+	return out
+}
+
+// Last returns the most recently rendered frame.
+func (sim *Simulator) Last() ([]RGB, bool) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	if len(sim.frames) == 0 {
+		return nil, false
+	}
+	return append([]RGB(nil), sim.frames[len(sim.frames)-1]...), true
+}
+
+// Reset discards all recorded frames.
+func (sim *Simulator) Reset() {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.frames = nil
+}
+
+func (sim *Simulator) record(leds []uint32) {
+	frame := make([]RGB, len(leds))
+	for i, c := range leds {
+		frame[i] = ColorToRGB(c)
+	}
+
+	sim.mu.Lock()
+	sim.frames = append(sim.frames, frame)
+	sim.mu.Unlock()
+
+	if f := sim.OnRender; f != nil {
+		f(append([]RGB(nil), frame...))
+	}
+}
+
+type simulatedStrip struct {
+	sim         *Simulator
+	count       int
+	leds        []uint32
+	initialized bool
+}
+
+func (s *simulatedStrip) Init() error {
+	if s.initialized {
+		return errors.New("strip already initialized")
+	}
+	s.leds = make([]uint32, s.count)
+	s.initialized = true
+	return nil
+}
+
+func (s *simulatedStrip) Leds(channel int) []uint32 {
+	if channel != 0 {
+		return nil
+	}
+	return s.leds
+}
+
+func (s *simulatedStrip) Render() error {
+	if !s.initialized {
+		return errors.New("strip not initialized")
+	}
+	s.sim.record(s.leds)
+	return nil
+}
+
+func (s *simulatedStrip) Wait() error {
+	return nil
+}
+
+func (s *simulatedStrip) Fini() {
+	s.initialized = false
+}
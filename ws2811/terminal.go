@@ -0,0 +1,105 @@
+package ws2811
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	ws281x "github.com/rpi-ws281x/rpi-ws281x-go"
+)
+
+// Terminal is a Driver that draws each LED as a truecolor ANSI block, one
+// LED per line, and redraws in place on every rendered frame.
+type Terminal struct {
+	// Out is where frames are drawn. Defaults to os.Stdout.
+	Out io.Writer
+	// Label, if set, returns text to show next to an LED.
+	Label func(index int, color RGB) string
+
+	mu    sync.Mutex
+	drawn int
+}
+
+func (term *Terminal) Open(opt *ws281x.Option) (Strip, error) {
+	if len(opt.Channels) == 0 {
+		return nil, errors.New("no channels configured")
+	}
+	return &terminalStrip{
+		term:  term,
+		count: opt.Channels[0].LedCount,
+	}, nil
+}
+
+func (term *Terminal) out() io.Writer {
+	if term.Out != nil {
+		return term.Out
+	}
+	return os.Stdout
+}
+
+func (term *Terminal) draw(leds []uint32) error {
+	term.mu.Lock()
+	defer term.mu.Unlock()
+
+	w := bufio.NewWriter(term.out())
+
+	if term.drawn > 0 {
+		fmt.Fprintf(w, "\x1b[%dA", term.drawn)
+	}
+
+	for i, c := range leds {
+		rgb := ColorToRGB(c)
+		fmt.Fprintf(w, "\x1b[2K%4d \x1b[48;2;%d;%d;%dm    \x1b[0m", i, rgb.Red, rgb.Green, rgb.Blue)
+		if f := term.Label; f != nil {
+			if lbl := f(i, rgb); lbl != "" {
+				fmt.Fprintf(w, " %s", lbl)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	term.drawn = len(leds)
+
+	return w.Flush()
+}
+
+type terminalStrip struct {
+	term        *Terminal
+	count       int
+	leds        []uint32
+	initialized bool
+}
+
+func (s *terminalStrip) Init() error {
+	if s.initialized {
+		return errors.New("strip already initialized")
+	}
+	s.leds = make([]uint32, s.count)
+	s.initialized = true
+	return nil
+}
+
+func (s *terminalStrip) Leds(channel int) []uint32 {
+	if channel != 0 {
+		return nil
+	}
+	return s.leds
+}
+
+func (s *terminalStrip) Render() error {
+	if !s.initialized {
+		return errors.New("strip not initialized")
+	}
+	return s.term.draw(s.leds)
+}
+
+func (s *terminalStrip) Wait() error {
+	return nil
+}
+
+func (s *terminalStrip) Fini() {
+	s.initialized = false
+}
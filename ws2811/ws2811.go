@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
 	ws281x "github.com/rpi-ws281x/rpi-ws281x-go"
 )
@@ -32,18 +36,90 @@ func (rgb RGB) ToColor() uint32 {
 	return uint32(uint32(rgb.Green)<<16 | uint32(rgb.Red)<<8 | uint32(rgb.Blue))
 }
 
+// Scale returns the color with each component multiplied by f.
+func (rgb RGB) Scale(f float64) RGB {
+	scale := func(c int) int {
+		v := int(math.Round(float64(c) * f))
+		switch {
+		case v < 0:
+			return 0
+		case v > 255:
+			return 255
+		}
+		return v
+	}
+	return RGB{
+		Red:   scale(rgb.Red),
+		Green: scale(rgb.Green),
+		Blue:  scale(rgb.Blue),
+	}
+}
+
+func (rgb RGB) String() string {
+	return fmt.Sprintf("#%02x%02x%02x", rgb.Red, rgb.Green, rgb.Blue)
+}
+
+// ParseRGB parses a color in hex ("#00ff00" or "00ff00") or decimal
+// ("0,255,0") form.
+func ParseRGB(s string) (RGB, error) {
+	s = strings.TrimSpace(s)
+
+	if parts := strings.Split(s, ","); len(parts) == 3 {
+		var out [3]int
+		for i, p := range parts {
+			v, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil || v < 0 || v > 255 {
+				return RGB{}, fmt.Errorf("invalid color: %s", s)
+			}
+			out[i] = v
+		}
+		return RGB{Red: out[0], Green: out[1], Blue: out[2]}, nil
+	}
+
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 {
+		return RGB{}, fmt.Errorf("invalid color: %s", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return RGB{}, fmt.Errorf("invalid color: %s", s)
+	}
+	return RGB{
+		Red:   int(v >> 16 & 0xff),
+		Green: int(v >> 8 & 0xff),
+		Blue:  int(v & 0xff),
+	}, nil
+}
+
+// ColorToRGB is the inverse of RGB.ToColor.
+func ColorToRGB(c uint32) RGB {
+	return RGB{
+		Red:   int(c >> 8 & 0xff),
+		Green: int(c >> 16 & 0xff),
+		Blue:  int(c & 0xff),
+	}
+}
+
 type Option func(*ws281x.ChannelOption)
 
 type Controller struct {
 	Logger  *slog.Logger
 	Options []Option
+	// Driver creates the LED strip. Defaults to the rpi-ws281x hardware driver.
+	Driver Driver
+	// FrameRate is the frames per second at which Serve evaluates effects.
+	// Zero uses DefaultFrameRate.
+	FrameRate int
+	// Transition is how long Serve crossfades from the displayed colors to a
+	// new State. Zero changes colors immediately.
+	Transition time.Duration
 }
 
 func RGBToColor(r int, g int, b int) uint32 {
 	return uint32(uint32(r)<<16 | uint32(g)<<8 | uint32(b))
 }
 
-func (ctrl *Controller) Render(drv *ws281x.WS2811, cats map[int]RGB) error {
+func (ctrl *Controller) Render(drv Strip, cats map[int]RGB) error {
 
 	leds := drv.Leds(0)
 
@@ -79,6 +155,34 @@ func (ctrl *Controller) DefaultOptions() []Option {
 	}
 }
 
+func (ctrl *Controller) driver() Driver {
+	if ctrl.Driver != nil {
+		return ctrl.Driver
+	}
+	return WS281x
+}
+
+func (ctrl *Controller) driverOptions() ws281x.Option {
+	drvopts := ws281x.DefaultOptions
+	drvopts.Channels = append([]ws281x.ChannelOption(nil), ws281x.DefaultOptions.Channels...)
+	ctrl.applyOptions(&drvopts, ctrl.DefaultOptions()...)
+	ctrl.applyOptions(&drvopts, ctrl.Options...)
+	return drvopts
+}
+
+func (ctrl *Controller) open(drvopts ws281x.Option) (Strip, error) {
+	drv, err := ctrl.driver().Open(&drvopts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create driver: %w", err)
+	}
+
+	if err := drv.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	return drv, nil
+}
+
 func (ctrl *Controller) applyOptions(drvopt *ws281x.Option, opts ...Option) {
 	for _, opt := range opts {
 		if opt == nil {
@@ -90,23 +194,19 @@ func (ctrl *Controller) applyOptions(drvopt *ws281x.Option, opts ...Option) {
 	}
 }
 
-func (ctrl *Controller) Serve(ctx context.Context, src chan (map[int]RGB)) error {
+// Serve renders the latest State received from src at the frame rate until
+// ctx is closed, then turns off all LEDs.
+func (ctrl *Controller) Serve(ctx context.Context, src chan State) error {
 
-	drvopts := ws281x.DefaultOptions
-	ctrl.applyOptions(&drvopts, ctrl.DefaultOptions()...)
-	ctrl.applyOptions(&drvopts, ctrl.Options...)
+	drvopts := ctrl.driverOptions()
 
 	if l := ctrl.Logger; l != nil {
 		l.Info("serving", "brightness", drvopts.Channels[0].Brightness, "ledCount", drvopts.Channels[0].LedCount, "gpioPin", drvopts.Channels[0].GpioPin)
 	}
 
-	drv, err := ws281x.MakeWS2811(&drvopts)
+	drv, err := ctrl.open(drvopts)
 	if err != nil {
-		return fmt.Errorf("failed to create driver: %w", err)
-	}
-
-	if err := drv.Init(); err != nil {
-		return fmt.Errorf("failed to initialize: %w", err)
+		return err
 	}
 
 	defer func() {
@@ -128,17 +228,43 @@ func (ctrl *Controller) Serve(ctx context.Context, src chan (map[int]RGB)) error
 		}
 	}()
 
+	var (
+		state    State
+		rendered map[int]RGB
+	)
+
+	ticker := time.NewTicker(time.Second / time.Duration(ctrl.frameRate()))
+	defer ticker.Stop()
+
+	render := func(frame map[int]RGB) {
+		if equalColors(frame, rendered) {
+			return
+		}
+		if err := ctrl.Render(drv, frame); err != nil {
+			if l := ctrl.Logger; l != nil {
+				l.Error("render failure", "error", err)
+			}
+			return
+		}
+		rendered = frame
+	}
+
 	for {
 		select {
-		case colors := <-src:
+		case state = <-src:
 			if l := ctrl.Logger; l != nil {
-				l.Debug("render", "categories", colors)
+				l.Debug("render", "state", state)
 			}
-			if err := ctrl.Render(drv, colors); err != nil {
-				if l := ctrl.Logger; l != nil {
-					l.Error("render failure", "error", err)
-				}
+			now := time.Now()
+			state = ctrl.transition(now, rendered, state)
+			// A new state is always rendered, even when unchanged.
+			rendered = nil
+			render(state.Colors(now))
+		case now := <-ticker.C:
+			if state == nil {
+				continue
 			}
+			render(state.Colors(now))
 		case <-ctx.Done():
 			if l := ctrl.Logger; l != nil {
 				l.Debug("context done", "error", ctx.Err())
@@ -148,7 +274,7 @@ func (ctrl *Controller) Serve(ctx context.Context, src chan (map[int]RGB)) error
 	}
 }
 
-func (ctrl *Controller) setAllLEDs(ctx context.Context, drv *ws281x.WS2811, color RGB) error {
+func (ctrl *Controller) setAllLEDs(ctx context.Context, drv Strip, color RGB) error {
 	leds := drv.Leds(0)
 
 	for i := 0; i < len(leds); i++ {
@@ -174,21 +300,15 @@ func (ctrl *Controller) setAllLEDs(ctx context.Context, drv *ws281x.WS2811, colo
 
 func (ctrl *Controller) SetAllLEDs(ctx context.Context, color RGB) error {
 
-	drvopts := ws281x.DefaultOptions
-	ctrl.applyOptions(&drvopts, ctrl.DefaultOptions()...)
-	ctrl.applyOptions(&drvopts, ctrl.Options...)
+	drvopts := ctrl.driverOptions()
 
 	if l := ctrl.Logger; l != nil {
 		l.Info("setting all LEDs", "brightness", drvopts.Channels[0].Brightness, "ledCount", drvopts.Channels[0].LedCount, "gpioPin", drvopts.Channels[0].GpioPin)
 	}
 
-	drv, err := ws281x.MakeWS2811(&drvopts)
+	drv, err := ctrl.open(drvopts)
 	if err != nil {
-		return fmt.Errorf("failed to create driver: %w", err)
-	}
-
-	if err := drv.Init(); err != nil {
-		return fmt.Errorf("failed to initialize: %w", err)
+		return err
 	}
 
 	defer func() {
@@ -0,0 +1,332 @@
+package ws2811_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrewmostello/metar-ws2811/ws2811"
+	ws281x "github.com/rpi-ws281x/rpi-ws281x-go"
+)
+
+func ledCount(n int) ws2811.Option {
+	return func(opt *ws281x.ChannelOption) {
+		opt.LedCount = n
+	}
+}
+
+func TestColorToRGB(t *testing.T) {
+	fixtures := []ws2811.RGB{
+		{Red: 0, Green: 0, Blue: 0},
+		{Red: 255, Green: 0, Blue: 0},
+		{Red: 0, Green: 255, Blue: 0},
+		{Red: 0, Green: 0, Blue: 255},
+		{Red: 12, Green: 34, Blue: 56},
+	}
+
+	for _, f := range fixtures {
+		if got := ws2811.ColorToRGB(f.ToColor()); got != f {
+			t.Fatalf("expected %v, got %v", f, got)
+		}
+	}
+}
+
+func TestControllerServe(t *testing.T) {
+
+	rendered := make(chan []ws2811.RGB, 16)
+
+	sim := &ws2811.Simulator{
+		OnRender: func(frame []ws2811.RGB) {
+			rendered <- frame
+		},
+	}
+
+	ctrl := &ws2811.Controller{
+		Driver:  sim,
+		Options: []ws2811.Option{ledCount(3)},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := make(chan ws2811.State)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- ctrl.Serve(ctx, src)
+	}()
+
+	red := ws2811.RGB{Red: 255}
+	src <- ws2811.State{1: ws2811.Static(red)}
+
+	select {
+	case frame := <-rendered:
+		exp := []ws2811.RGB{ws2811.Off, red, ws2811.Off}
+		if len(frame) != len(exp) {
+			t.Fatalf("expected %d LEDs, got %d", len(exp), len(frame))
+		}
+		for i := range exp {
+			if frame[i] != exp[i] {
+				t.Fatalf("expected %v at %d, got %v", exp[i], i, frame[i])
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for frame")
+	}
+
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	last, ok := sim.Last()
+	if !ok {
+		t.Fatal("expected a final frame")
+	}
+	for i, rgb := range last {
+		if rgb != ws2811.Off {
+			t.Fatalf("expected LED %d off after stopping, got %v", i, rgb)
+		}
+	}
+
+	if n := len(sim.Frames()); n != 2 {
+		t.Fatalf("expected 2 frames, got %d", n)
+	}
+}
+
+func TestControllerSetAllLEDs(t *testing.T) {
+
+	sim := &ws2811.Simulator{}
+
+	ctrl := &ws2811.Controller{
+		Driver:  sim,
+		Options: []ws2811.Option{ledCount(5)},
+	}
+
+	blue := ws2811.RGB{Blue: 255}
+
+	if err := ctrl.SetAllLEDs(context.Background(), blue); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	last, ok := sim.Last()
+	if !ok {
+		t.Fatal("expected a frame")
+	}
+	if len(last) != 5 {
+		t.Fatalf("expected 5 LEDs, got %d", len(last))
+	}
+	for i, rgb := range last {
+		if rgb != blue {
+			t.Fatalf("expected %v at %d, got %v", blue, i, rgb)
+		}
+	}
+}
+
+func TestTerminal(t *testing.T) {
+
+	var out bytes.Buffer
+
+	ctrl := &ws2811.Controller{
+		Driver: &ws2811.Terminal{
+			Out: &out,
+			Label: func(index int, color ws2811.RGB) string {
+				if index == 1 {
+					return "KBOS VFR"
+				}
+				return ""
+			},
+		},
+		Options: []ws2811.Option{ledCount(2)},
+	}
+
+	if err := ctrl.SetAllLEDs(context.Background(), ws2811.RGB{Red: 1, Green: 2, Blue: 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), out.String())
+	}
+	if !strings.Contains(lines[0], "\x1b[48;2;1;2;3m") {
+		t.Fatalf("expected truecolor block, got %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], " KBOS VFR") {
+		t.Fatalf("expected label, got %q", lines[1])
+	}
+}
+
+func TestControllerEffects(t *testing.T) {
+
+	rendered := make(chan []ws2811.RGB, 64)
+
+	ctrl := &ws2811.Controller{
+		Driver: &ws2811.Simulator{
+			OnRender: func(frame []ws2811.RGB) {
+				select {
+				case rendered <- frame:
+				default:
+				}
+			},
+		},
+		Options:   []ws2811.Option{ledCount(2)},
+		FrameRate: 100,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := make(chan ws2811.State)
+
+	go ctrl.Serve(ctx, src)
+
+	red := ws2811.RGB{Red: 255}
+	white := ws2811.RGB{Red: 255, Green: 255, Blue: 255}
+
+	src <- ws2811.State{
+		0: ws2811.Static(red),
+		1: ws2811.Blink{Color: white, Off: red, Period: 100 * time.Millisecond},
+	}
+
+	var sawRed, sawWhite bool
+	deadline := time.After(time.Second)
+
+	for !sawRed || !sawWhite {
+		select {
+		case frame := <-rendered:
+			if frame[0] != red {
+				t.Fatalf("expected %v at 0, got %v", red, frame[0])
+			}
+			switch frame[1] {
+			case red:
+				sawRed = true
+			case white:
+				sawWhite = true
+			}
+		case <-deadline:
+			t.Fatalf("expected frames between states, saw red %v white %v", sawRed, sawWhite)
+		}
+	}
+}
+
+func TestControllerTransition(t *testing.T) {
+
+	rendered := make(chan []ws2811.RGB, 256)
+
+	ctrl := &ws2811.Controller{
+		Driver: &ws2811.Simulator{
+			OnRender: func(frame []ws2811.RGB) {
+				select {
+				case rendered <- frame:
+				default:
+				}
+			},
+		},
+		Options:    []ws2811.Option{ledCount(1)},
+		FrameRate:  100,
+		Transition: 200 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := make(chan ws2811.State)
+
+	go ctrl.Serve(ctx, src)
+
+	red := ws2811.RGB{Red: 255}
+	blue := ws2811.RGB{Blue: 255}
+
+	// The first state has nothing to fade from.
+	src <- ws2811.State{0: ws2811.Static(red)}
+	if frame := <-rendered; frame[0] != red {
+		t.Fatalf("expected %v, got %v", red, frame[0])
+	}
+
+	src <- ws2811.State{0: ws2811.Static(blue)}
+
+	var between int
+	deadline := time.After(time.Second)
+
+	for {
+		select {
+		case frame := <-rendered:
+			switch frame[0] {
+			case red:
+			case blue:
+				if between == 0 {
+					t.Fatal("expected colors between red and blue")
+				}
+				return
+			default:
+				between++
+			}
+		case <-deadline:
+			t.Fatalf("expected fade to %v, saw %d frames between", blue, between)
+		}
+	}
+}
+
+// recordEffect records the times it is evaluated at.
+type recordEffect struct {
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func (r *recordEffect) At(t time.Time) ws2811.RGB {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.times = append(r.times, t)
+	return ws2811.RGB{Green: 255}
+}
+
+func TestControllerTransitionEvaluatesOnce(t *testing.T) {
+
+	rendered := make(chan []ws2811.RGB, 256)
+
+	ctrl := &ws2811.Controller{
+		Driver: &ws2811.Simulator{
+			OnRender: func(frame []ws2811.RGB) {
+				select {
+				case rendered <- frame:
+				default:
+				}
+			},
+		},
+		Options:    []ws2811.Option{ledCount(1)},
+		FrameRate:  100,
+		Transition: 50 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := make(chan ws2811.State)
+
+	go ctrl.Serve(ctx, src)
+
+	src <- ws2811.State{0: ws2811.Static(ws2811.RGB{Red: 255})}
+	<-rendered
+
+	eff := &recordEffect{}
+	src <- ws2811.State{0: eff}
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	eff.mu.Lock()
+	defer eff.mu.Unlock()
+
+	if len(eff.times) == 0 {
+		t.Fatal("expected the effect to be evaluated")
+	}
+	for i := 1; i < len(eff.times); i++ {
+		if !eff.times[i].After(eff.times[i-1]) {
+			t.Fatalf("expected one evaluation per frame, got %v", eff.times)
+		}
+	}
+}